@@ -1,10 +1,13 @@
 package specstack
 
 import (
+	"context"
 	"errors"
 
+	"github.com/endiangroup/specstack/discussion"
 	"github.com/endiangroup/specstack/metadata"
 	"github.com/endiangroup/specstack/repository"
+	"github.com/endiangroup/specstack/specification"
 )
 
 var (
@@ -22,22 +25,173 @@ type ConfigGetListSetter interface {
 	SetConfiguration(name, value string) error
 }
 
-type MetadataGetAdder interface {
-	AddMetadataToStory(storyName, key, value string) error
-	AddMetadataToScenario(scenarioName, storyName, key, value string) error
-	GetStoryMetadata(string) ([]*metadata.Entry, error)
-	GetScenarioMetadata(scenario string, story string) ([]*metadata.Entry, error)
+type MetadataGetter interface {
+	GetStoryMetadata(story string, filters ...specification.QueryMapFunc) ([]*metadata.Entry, error)
+	GetScenarioMetadata(scenario string, story string, filters ...specification.QueryMapFunc) ([]*metadata.Entry, error)
+	GetActorMetadata(actor string) ([]*metadata.Entry, error)
+	GetStepMetadata(step, scenario, story string, filters ...specification.QueryMapFunc) ([]*metadata.Entry, error)
+}
+
+// A MetadataTarget names what metadata is attached to: an actor, a story, a
+// scenario (optionally of a story) or a step of a scenario. Filters narrow the
+// stories or scenarios that are considered.
+type MetadataTarget struct {
+	Actor    string
+	Story    string
+	Scenario string
+	Step     string
+	Filters  []specification.QueryMapFunc
+}
+
+type MetadataEditor interface {
+	SetMetadata(target MetadataTarget, entries ...*metadata.Entry) error
+	RemoveMetadata(target MetadataTarget, keys ...string) error
+	GetMetadataHistory(target MetadataTarget) ([]*metadata.Entry, error)
+}
+
+// A MetadataViolation is an object whose metadata breaks the project's
+// metadata schema. Err lists the problems.
+type MetadataViolation struct {
+	Kind     string
+	Name     string
+	Location string
+	Err      error
+}
+
+type MetadataFinder interface {
+	MetadataStoryFilters(predicates ...*metadata.Predicate) []specification.QueryMapFunc
+	MetadataScenarioFilters(predicates ...*metadata.Predicate) []specification.QueryMapFunc
+}
+
+// A Discussion is the threads of conversation about a story or scenario.
+type Discussion struct {
+	Kind    string
+	Label   string
+	Threads []*discussion.Thread
+}
+
+type Discusser interface {
+	StartDiscussion(target MetadataTarget, message string) (string, error)
+	ReplyToDiscussion(target MetadataTarget, thread, message string) error
+	ResolveDiscussion(target MetadataTarget, thread string) error
+	ReopenDiscussion(target MetadataTarget, thread string) error
+	ListDiscussions(target MetadataTarget) ([]Discussion, error)
+}
+
+// A ProgressCount is how many scenarios of a story, or of the stories that
+// serve an actor, are in each state.
+type ProgressCount struct {
+	Name   string
+	Counts map[string]int
+}
+
+// A ProgressReport rolls up the states of scenarios per story and per actor.
+// States lists the states of the workflow, followed by any others scenarios
+// are in.
+type ProgressReport struct {
+	States  []string
+	Stories []ProgressCount
+	Actors  []ProgressCount
+}
+
+type ProgressTracker interface {
+	SetProgress(target MetadataTarget, state string) error
+	Progress() (*ProgressReport, error)
+}
+
+type MetadataChecker interface {
+	CheckMetadata() ([]MetadataViolation, error)
+}
+
+// A MetadataRecord is the current metadata of a story or scenario, along with
+// what identifies it, as exported and imported in bulk. Story is the name of a
+// scenario's story, and Hash that of the object the metadata is keyed by.
+type MetadataRecord struct {
+	Kind     string
+	ID       string
+	Story    string
+	Name     string
+	File     string
+	Line     int
+	Hash     string
+	Metadata []*metadata.Entry
+}
+
+// Label names the record's story, or its scenario within its story.
+func (r *MetadataRecord) Label() string {
+	if r.Story == "" {
+		return r.Name
+	}
+	return r.Story + "/" + r.Name
+}
+
+// How the target of an imported record was found.
+const (
+	MatchID    = "id"
+	MatchName  = "name"
+	MatchFuzzy = "fuzzy match"
+)
+
+// A MetadataImport reports what importing a record did, or would do. Target is
+// the record of what it matched, found by Match, and Changed the entries that
+// differ from its current metadata. Err says why it can't be imported.
+type MetadataImport struct {
+	Record  *MetadataRecord
+	Target  *MetadataRecord
+	Match   string
+	Changed []*metadata.Entry
+	Err     error
+}
+
+type MetadataExportImporter interface {
+	ExportMetadata() ([]*MetadataRecord, error)
+	ImportMetadata(records []*MetadataRecord, dryRun bool) ([]MetadataImport, error)
+}
+
+type ActorLister interface {
+	ListActors() ([]*specification.Actor, error)
+}
+
+type SpecificationQuerier interface {
+	StoryFilters(expression string) ([]specification.QueryMapFunc, error)
+	ScenarioFilters(expression string) ([]specification.QueryMapFunc, error)
+	QueryStories(filters ...specification.QueryMapFunc) ([]*specification.Story, error)
+	QueryScenarios(filters ...specification.QueryMapFunc) ([]*specification.Scenario, error)
+}
+
+type SpecificationDiffer interface {
+	DiffSpecification(from, to string) ([]specification.ScenarioChange, error)
+}
+
+type ScenarioIDAssigner interface {
+	AssignScenarioIDs() ([]specification.IDAssignment, error)
+	CheckScenarioIDs() error
+}
+
+type RevisionSelector interface {
+	SelectRevision(revision string) error
 }
 
 type PushPuller interface {
 	Push() error
-	Pull() error
+	Pull() (*repository.MetadataMerge, error)
+}
+
+type PushQueuer interface {
+	PendingPushes() ([]metadata.PendingPush, error)
+	RetryPendingPushes(ctx context.Context) (int, error)
 }
 
 type MetadataTransferer interface {
 	TransferScenarioMetadata() error
 }
 
+type HookManager interface {
+	InstallHooks() ([]repository.HookStatus, error)
+	UninstallHooks() ([]repository.HookStatus, error)
+	HookStatuses() ([]repository.HookStatus, error)
+}
+
 type RepoHooker interface {
 	RepoPrePushHook() error
 	RepoPostMergeHook() error
@@ -45,13 +199,26 @@ type RepoHooker interface {
 }
 
 type Application struct {
-	ConfigAsserter      ConfigAsserter
-	ConfigGetListSetter ConfigGetListSetter
-	Repository          repository.Repository
-	MetadataGetAdder    MetadataGetAdder
-	PushPuller          PushPuller
-	MetadataTransferer  MetadataTransferer
-	RepoHooker          RepoHooker
+	ConfigAsserter         ConfigAsserter
+	ConfigGetListSetter    ConfigGetListSetter
+	Repository             repository.Repository
+	MetadataGetter         MetadataGetter
+	MetadataEditor         MetadataEditor
+	SpecificationQuerier   SpecificationQuerier
+	SpecificationDiffer    SpecificationDiffer
+	ActorLister            ActorLister
+	ScenarioIDAssigner     ScenarioIDAssigner
+	MetadataChecker        MetadataChecker
+	MetadataFinder         MetadataFinder
+	MetadataExportImporter MetadataExportImporter
+	Discusser              Discusser
+	ProgressTracker        ProgressTracker
+	RevisionSelector       RevisionSelector
+	PushPuller             PushPuller
+	PushQueuer             PushQueuer
+	MetadataTransferer     MetadataTransferer
+	RepoHooker             RepoHooker
+	HookManager            HookManager
 }
 
 func (a *Application) Initialise() error {
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/endiangroup/specstack/specification"
+)
+
+func printActors(w io.Writer, actors []*specification.Actor) error {
+	if len(actors) == 0 {
+		_, err := fmt.Fprintln(w, "No actors found")
+		return err
+	}
+
+	for _, actor := range actors {
+		if err := printActor(w, actor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printActor lists an actor's goals with the stories serving each, then the
+// actor's stories that serve none of them.
+func printActor(w io.Writer, actor *specification.Actor) error {
+	if _, err := fmt.Fprintf(w, "%s (%s)\n", actor.Name, actor.SourceIdentifier); err != nil {
+		return err
+	}
+
+	served := map[*specification.Story]bool{}
+	for _, goal := range actor.Goals {
+		if _, err := fmt.Fprintf(w, "  Goal: %s\n", goal.Name); err != nil {
+			return err
+		}
+		for _, story := range goal.Stories {
+			served[story] = true
+		}
+		if err := printActorStories(w, goal.Stories); err != nil {
+			return err
+		}
+	}
+
+	other := []*specification.Story{}
+	for _, story := range actor.Stories {
+		if !served[story] {
+			other = append(other, story)
+		}
+	}
+	if len(other) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "  Stories without a goal:"); err != nil {
+		return err
+	}
+	return printActorStories(w, other)
+}
+
+func printActorStories(w io.Writer, stories []*specification.Story) error {
+	for _, story := range stories {
+		if _, err := fmt.Fprintf(w, "    - %s (%s)\n", story.Name, story.SourceIdentifier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -16,3 +16,9 @@ func IsKeyEqualsValueFormat(arg string) error {
 
 	return nil
 }
+
+// IsMetadataFormat accepts key=value, or key:=<json> for a typed value.
+func IsMetadataFormat(arg string) error {
+	_, err := parseMetadataArg(arg)
+	return err
+}
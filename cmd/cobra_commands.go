@@ -1,6 +1,13 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"strings"
+
+	"github.com/endiangroup/specstack/metadata"
+	"github.com/spf13/cobra"
+)
+
+var metadataFormats = strings.Join(metadata.Formats, ", ")
 
 func noop(*cobra.Command, []string) {}
 
@@ -12,18 +19,36 @@ func WireUpCobraHarness(harness *CobraHarness) *cobra.Command {
 	root.SetOutput(harness.stdout)
 
 	root.AddCommand(
+		commandActors(harness),
 		commandConfig(harness),
+		commandDiff(harness),
+		commandDiscuss(harness),
 		commandGitHooks(harness),
+		commandHooks(harness),
+		commandIDs(harness),
 		commandMetadata(harness),
+		commandProgress(harness),
 		commandPull(harness),
 		commandPush(harness),
+		commandQuery(harness),
+		commandStatus(harness),
 	)
 
+	root.PersistentFlags().String("rev", "", "Read the specification as it was at a commit, tag or branch")
 	root.PersistentPreRunE = harness.PersistentPreRunE
 
 	return root
 }
 
+func commandActors(harness *CobraHarness) *cobra.Command {
+	return &cobra.Command{
+		Use:   "actors",
+		Short: "List actors, their goals and the stories that serve them",
+		Args:  cobra.NoArgs,
+		RunE:  harness.Actors,
+	}
+}
+
 func commandConfig(harness *CobraHarness) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "config",
@@ -78,6 +103,70 @@ func commandGitHooks(harness *CobraHarness) *cobra.Command {
 	return root
 }
 
+func commandHooks(harness *CobraHarness) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage the git hooks that keep metadata in sync",
+		Long: "Hooks are written to core.hooksPath if it is set. Spec is added to existing shell script hooks " +
+			"in a marked block, and other hooks are chained after a dispatcher that runs spec first.",
+	}
+	install := &cobra.Command{
+		Use:     "install",
+		Args:    cobra.NoArgs,
+		Short:   "Make the git hooks run spec, chaining into any hooks already there",
+		Example: "$ spec hooks install",
+		RunE:    harness.HooksInstall,
+	}
+	uninstall := &cobra.Command{
+		Use:     "uninstall",
+		Args:    cobra.NoArgs,
+		Short:   "Stop the git hooks running spec, restoring the hooks spec was added to",
+		Example: "$ spec hooks uninstall",
+		RunE:    harness.HooksUninstall,
+	}
+	status := &cobra.Command{
+		Use:     "status",
+		Args:    cobra.NoArgs,
+		Short:   "Show whether each git hook runs spec",
+		Example: "$ spec hooks status",
+		RunE:    harness.HooksStatus,
+	}
+
+	root.AddCommand(install, uninstall, status)
+
+	return root
+}
+
+func commandIDs(harness *CobraHarness) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "ids",
+		Short: "Manage stable scenario IDs",
+	}
+	assign := &cobra.Command{
+		Use:     "assign",
+		Args:    cobra.NoArgs,
+		Short:   "Tag every scenario that has no ID with a new one",
+		Example: "$ spec ids assign",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	check := &cobra.Command{
+		Use:     "check",
+		Args:    cobra.NoArgs,
+		Short:   "Fail if scenarios share an ID, or stories share an ID or narrative and so their metadata",
+		Example: "$ spec ids check",
+	}
+
+	root.AddCommand(
+		assign,
+		check,
+	)
+
+	assign.RunE = harness.IDsAssign
+	check.RunE = harness.IDsCheck
+
+	return root
+}
+
 func commandMetadata(harness *CobraHarness) *cobra.Command {
 	root := &cobra.Command{
 		Use:     "metadata",
@@ -86,18 +175,72 @@ func commandMetadata(harness *CobraHarness) *cobra.Command {
 	}
 	add := &cobra.Command{
 		Use:     "add",
-		Short:   "Add metadata to a story or scenario",
-		Example: "$ spec metadata add --story my_story key=value",
+		Short:   "Add metadata to a story, scenario or actor",
+		Example: "$ spec metadata add --story my_story key=value estimate:=3",
 		PreRunE: harness.SnapshotScenarioMetadata,
 	}
 	list := &cobra.Command{
 		Use:     "list",
 		Args:    cobra.ExactArgs(0),
 		Aliases: []string{"ls"},
-		Short:   "Show metadata for a story or scenario",
+		Short:   "Show metadata for a story, scenario or actor",
 		Example: "$ spec metadata list --story my_story",
 		PreRunE: harness.SnapshotScenarioMetadata,
 	}
+	set := &cobra.Command{
+		Use:   "set",
+		Short: "Replace the values of metadata on a story, scenario or actor",
+		Example: "$ spec metadata set --story my_story key=value\n" +
+			"$ spec metadata list --story a --format json | spec metadata set --story b --stdin",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	rm := &cobra.Command{
+		Use:     "rm <key>...",
+		Args:    cobra.MinimumNArgs(1),
+		Aliases: []string{"remove"},
+		Short:   "Remove metadata from a story, scenario or actor",
+		Example: "$ spec metadata rm --story my_story key",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	edit := &cobra.Command{
+		Use:     "edit",
+		Args:    cobra.NoArgs,
+		Short:   "Edit the metadata of a story, scenario or actor in $EDITOR",
+		Example: "$ spec metadata edit --story my_story",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	check := &cobra.Command{
+		Use:     "check",
+		Args:    cobra.NoArgs,
+		Short:   "Audit existing metadata against the project's metadata schema",
+		Example: "$ spec metadata check",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	find := &cobra.Command{
+		Use:   "find <predicate>...",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "List the stories or scenarios whose metadata matches every predicate",
+		Long: "Predicates test the current value of a key: key (is set), !key (is not set), key=value, " +
+			"key!=value, key~regexp, and numeric comparisons key>n, key>=n, key<n and key<=n.",
+		Example: "$ spec metadata find --type scenario 'owner=alice' 'estimate>3'",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	export := &cobra.Command{
+		Use:     "export",
+		Args:    cobra.NoArgs,
+		Short:   "Print the metadata of every story and scenario",
+		Example: "$ spec metadata export --format csv > metadata.csv",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Record exported metadata on the stories and scenarios it matches",
+		Long: "Records are matched to stories and scenarios by ID, then by name, then by the closest name. " +
+			"Only values that differ are recorded, and nothing is removed. Use - to read from stdin.",
+		Example: "$ spec metadata import metadata.csv --dry-run",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
 	commit := &cobra.Command{
 		Use:     "commit",
 		Example: "$ spec metadata commit",
@@ -107,14 +250,44 @@ func commandMetadata(harness *CobraHarness) *cobra.Command {
 	root.AddCommand(
 		add,
 		list,
+		set,
+		rm,
+		edit,
+		check,
+		find,
+		export,
+		importCmd,
 		commit,
 	)
 
 	root.PersistentFlags().String("story", "", "")
 	root.PersistentFlags().String("scenario", "", "")
+	root.PersistentFlags().String("actor", "", "")
+	root.PersistentFlags().String("step", "", "Step of the scenario, by 1-based index or text")
+	root.PersistentFlags().String("tags", "", "Tag expression to filter by, e.g. '@wip and not @slow'")
+	root.PersistentFlags().String("query", "", "Query expression to filter by, e.g. 'tag:@api and steps>3'")
 	add.RunE = harness.MetadataAdd
-	add.Args = harness.SetKeyValueArgs
+	add.Args = harness.MetadataArgs
 	list.RunE = harness.MetadataList
+	list.Flags().Bool("history", false, "Show every value ever recorded, including removed ones")
+	list.Flags().String("author", "", "Only show metadata written by an author, by name or email")
+	list.Flags().String("format", metadata.FormatPlain, "Output format: "+metadataFormats)
+	set.RunE = harness.MetadataSet
+	set.Args = harness.MetadataSetArgs
+	set.Flags().Bool("stdin", false, "Read the metadata to set from stdin, as printed by list")
+	set.Flags().String("format", metadata.FormatJSON, "Format of the metadata read with --stdin: "+metadataFormats)
+	rm.RunE = harness.MetadataRemove
+	edit.RunE = harness.MetadataEdit
+	edit.Flags().String("format", metadata.FormatFull, "Output format: "+metadataFormats)
+	check.RunE = harness.MetadataCheck
+	find.RunE = harness.MetadataFind
+	find.Flags().String("type", "scenario", "What to list: story or scenario")
+	find.Flags().String("format", "table", "Output format: table or json")
+	export.RunE = harness.MetadataExport
+	export.Flags().String("format", exportFormatJSON, "Output format: "+strings.Join(exportFormats, ", "))
+	importCmd.RunE = harness.MetadataImport
+	importCmd.Flags().String("format", "", "Format of the file, by default guessed from its extension")
+	importCmd.Flags().Bool("dry-run", false, "Report what would be imported without recording anything")
 
 	return root
 }
@@ -130,6 +303,15 @@ func commandPull(harness *CobraHarness) *cobra.Command {
 	return root
 }
 
+func commandStatus(harness *CobraHarness) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show metadata changes waiting to be pushed",
+		Args:  cobra.NoArgs,
+		RunE:  harness.Status,
+	}
+}
+
 func commandPush(harness *CobraHarness) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "push",
@@ -140,3 +322,123 @@ func commandPush(harness *CobraHarness) *cobra.Command {
 
 	return root
 }
+
+func commandDiscuss(harness *CobraHarness) *cobra.Command {
+	root := &cobra.Command{
+		Use:     "discuss <message>",
+		Args:    cobra.ExactArgs(1),
+		Short:   "Start a discussion thread on a story or scenario",
+		Example: "$ spec discuss --scenario my_scenario 'Should this cover refunds?'",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	reply := &cobra.Command{
+		Use:     "reply <thread> <message>",
+		Args:    cobra.ExactArgs(2),
+		Short:   "Reply to a discussion thread",
+		Example: "$ spec discuss reply --scenario my_scenario 1a2b 'Not yet'",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	resolve := &cobra.Command{
+		Use:     "resolve <thread>",
+		Args:    cobra.ExactArgs(1),
+		Short:   "Mark a discussion thread as resolved",
+		Example: "$ spec discuss resolve --scenario my_scenario 1a2b",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	reopen := &cobra.Command{
+		Use:     "reopen <thread>",
+		Args:    cobra.ExactArgs(1),
+		Short:   "Reopen a resolved discussion thread",
+		Example: "$ spec discuss reopen --scenario my_scenario 1a2b",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	list := &cobra.Command{
+		Use:     "list",
+		Args:    cobra.NoArgs,
+		Aliases: []string{"ls"},
+		Short:   "Show the open discussion threads on a story or scenario, or on all of them",
+		Example: "$ spec discuss list --scenario my_scenario",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	root.AddCommand(
+		reply,
+		resolve,
+		reopen,
+		list,
+	)
+
+	root.PersistentFlags().String("story", "", "")
+	root.PersistentFlags().String("scenario", "", "")
+	root.PersistentFlags().String("actor", "", "")
+	root.PersistentFlags().String("step", "", "Step of the scenario, by 1-based index or text")
+	root.PersistentFlags().String("tags", "", "Tag expression to filter by, e.g. '@wip and not @slow'")
+	root.PersistentFlags().String("query", "", "Query expression to filter by, e.g. 'tag:@api and steps>3'")
+	root.RunE = harness.Discuss
+	reply.RunE = harness.DiscussReply
+	resolve.RunE = harness.DiscussResolve
+	reopen.RunE = harness.DiscussReopen
+	list.RunE = harness.DiscussList
+	list.Flags().Bool("all", false, "Show resolved threads too")
+
+	return root
+}
+
+func commandProgress(harness *CobraHarness) *cobra.Command {
+	root := &cobra.Command{
+		Use:     "progress",
+		Args:    cobra.NoArgs,
+		Short:   "Show how many scenarios are in each state of the workflow, per story and per actor",
+		Example: "$ spec progress",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	set := &cobra.Command{
+		Use:   "set <scenario> <state>",
+		Args:  cobra.ExactArgs(2),
+		Short: "Move a scenario to a state of the workflow",
+		Long: "The workflow is set with project.workflow, as comma separated paths of states joined by >. " +
+			"Scenarios start in the first state, and can only move along the workflow's transitions.",
+		Example: "$ spec progress set my_story/my_scenario in-progress",
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+	root.AddCommand(set)
+
+	root.RunE = harness.Progress
+	set.RunE = harness.ProgressSet
+	set.Flags().String("story", "", "")
+	set.Flags().String("tags", "", "Tag expression to filter by, e.g. '@wip and not @slow'")
+	set.Flags().String("query", "", "Query expression to filter by, e.g. 'tag:@api and steps>3'")
+
+	return root
+}
+
+func commandQuery(harness *CobraHarness) *cobra.Command {
+	root := &cobra.Command{
+		Use:     "query [expression]",
+		Short:   "List stories or scenarios matching a query",
+		Example: `$ spec query --type scenario 'story~"login" and tag:@api and meta:status=open and steps>10'`,
+		PreRunE: harness.SnapshotScenarioMetadata,
+	}
+
+	root.Flags().String("type", "scenario", "What to list: story or scenario")
+	root.Flags().String("format", "table", "Output format: table or json")
+	root.Flags().String("tags", "", "Tag expression to filter by, e.g. '@wip and not @slow'")
+	root.RunE = harness.Query
+
+	return root
+}
+
+func commandDiff(harness *CobraHarness) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "diff <rev-a> [<rev-b>]",
+		Short: "Describe how scenarios changed between two revisions",
+		Long: "Compares the specification at rev-a with rev-b, or with the working tree if rev-b is omitted, " +
+			"and lists the scenarios that were added, removed, modified, renamed or moved.",
+		Example: "$ spec diff v1.2 --format markdown",
+		Args:    cobra.RangeArgs(1, 2),
+	}
+
+	root.Flags().String("format", "text", "Output format: text, json or markdown")
+	root.RunE = harness.Diff
+
+	return root
+}
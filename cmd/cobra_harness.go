@@ -1,17 +1,29 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/endiangroup/specstack"
 	"github.com/endiangroup/specstack/errors"
 	"github.com/endiangroup/specstack/metadata"
+	"github.com/endiangroup/specstack/repository"
+	"github.com/endiangroup/specstack/specification"
 	"github.com/spf13/cobra"
 )
 
+var errStepWithoutScenario = fmt.Errorf("specify the scenario of the step with --scenario")
+
+// pendingPushTimeout bounds how long a command waits on retrying a push, so
+// that an unreachable remote doesn't hold every command up.
+const pendingPushTimeout = 10 * time.Second
+
 func NewCliErr(exitCode int, err error) CliErr {
 	return CliErr{ExitCode: exitCode, Err: err}
 }
@@ -71,11 +83,110 @@ func (c *CobraHarness) flagValueString(cmd *cobra.Command, name string) string {
 	return cmd.Flag(name).Value.String()
 }
 
+func (c *CobraHarness) optionalFlagValueString(cmd *cobra.Command, name string) string {
+	if flag := cmd.Flag(name); flag != nil {
+		return flag.Value.String()
+	}
+	return ""
+}
+
+// storyFilters builds story query filters from the --tags and --query flags,
+// if the command has them.
+func (c *CobraHarness) storyFilters(cmd *cobra.Command) ([]specification.QueryMapFunc, error) {
+	filters := []specification.QueryMapFunc{}
+
+	if tags := c.optionalFlagValueString(cmd, "tags"); tags != "" {
+		expression, err := specification.ParseTagExpression(tags)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, specification.MapStoryTags(expression))
+	}
+
+	if query := c.optionalFlagValueString(cmd, "query"); query != "" {
+		queryFilters, err := c.app.SpecificationQuerier.StoryFilters(query)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, queryFilters...)
+	}
+
+	return filters, nil
+}
+
+// scenarioFilters builds scenario query filters from the --tags and --query
+// flags, if the command has them.
+func (c *CobraHarness) scenarioFilters(cmd *cobra.Command) ([]specification.QueryMapFunc, error) {
+	filters := []specification.QueryMapFunc{}
+
+	if tags := c.optionalFlagValueString(cmd, "tags"); tags != "" {
+		expression, err := specification.ParseTagExpression(tags)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, specification.MapScenarioTags(expression))
+	}
+
+	if query := c.optionalFlagValueString(cmd, "query"); query != "" {
+		queryFilters, err := c.app.SpecificationQuerier.ScenarioFilters(query)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, queryFilters...)
+	}
+
+	return filters, nil
+}
+
+/*
+retriesPendingPushes reports whether a command retries pushing changes that
+couldn't be pushed before. Commands that only report on the changes don't,
+and neither does push, which pushes them anyway. Hooks do, so that the
+changes are pushed even when no other command is run.
+*/
+func retriesPendingPushes(cmd *cobra.Command) bool {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+
+	switch cmd.Name() {
+	case "status", "hooks", "push":
+		return false
+	}
+	return true
+}
+
+// retryPendingPushes pushes changes that couldn't be pushed before. A failed
+// retry leaves them queued for spec status to show.
+func (c *CobraHarness) retryPendingPushes() {
+	ctx, cancel := context.WithTimeout(context.Background(), pendingPushTimeout)
+	defer cancel()
+
+	pushed, err := c.app.PushQueuer.RetryPendingPushes(ctx)
+	switch {
+	case err != nil:
+		reason := strings.SplitN(err.Error(), "\n", 2)[0]
+		fmt.Fprintf(c.stderr, "Could not push metadata changes that had been waiting: %s\n", reason)
+	case pushed > 0:
+		fmt.Fprintf(c.stderr, "Pushed %s that had been waiting\n", plural(pushed, "metadata change", "metadata changes"))
+	}
+}
+
 func (c *CobraHarness) PersistentPreRunE(cmd *cobra.Command, args []string) error {
 	if err := c.app.Initialise(); err != nil {
 		return c.error(cmd, err)
 	}
 
+	if retriesPendingPushes(cmd) {
+		c.retryPendingPushes()
+	}
+
+	if revision := c.optionalFlagValueString(cmd, "rev"); revision != "" {
+		if err := c.app.RevisionSelector.SelectRevision(revision); err != nil {
+			return c.error(cmd, err)
+		}
+	}
+
 	return nil
 }
 
@@ -123,34 +234,42 @@ func (c *CobraHarness) SetKeyValueArgs(cmd *cobra.Command, args []string) error
 
 }
 
-func (c *CobraHarness) ConfigSet(cmd *cobra.Command, args []string) error {
-	keyValueParts := strings.Split(args[0], "=")
+// MetadataSetArgs validates the arguments of metadata set, which come from
+// stdin instead when --stdin is set.
+func (c *CobraHarness) MetadataSetArgs(cmd *cobra.Command, args []string) error {
+	if stdin, _ := cmd.Flags().GetBool("stdin"); !stdin {
+		return c.MetadataArgs(cmd, args)
+	}
 
-	err := c.app.ConfigGetListSetter.SetConfiguration(keyValueParts[0], keyValueParts[1])
-	if err != nil {
+	if err := cobra.NoArgs(cmd, args); err != nil {
 		return c.error(cmd, err)
 	}
-
 	return nil
 }
 
-func (c *CobraHarness) addMetadataToScenario(scenarioName, storyName string, args []string) error {
+// MetadataArgs checks that every argument is key=value or key:=<json>.
+func (c *CobraHarness) MetadataArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.MinimumNArgs(1)(cmd, args); err != nil {
+		return c.error(cmd, err)
+	}
+
 	for _, arg := range args {
-		kv := strings.Split(arg, "=")
-		if err := c.app.MetadataGetAdder.AddMetadataToScenario(scenarioName, storyName, kv[0], kv[1]); err != nil {
-			return err
+		if err := IsMetadataFormat(arg); err != nil {
+			return c.error(cmd, err)
 		}
 	}
+
 	return nil
 }
 
-func (c *CobraHarness) addMetadataToStory(storyName string, args []string) error {
-	for _, arg := range args {
-		kv := strings.Split(arg, "=")
-		if err := c.app.MetadataGetAdder.AddMetadataToStory(storyName, kv[0], kv[1]); err != nil {
-			return err
-		}
+func (c *CobraHarness) ConfigSet(cmd *cobra.Command, args []string) error {
+	keyValueParts := strings.Split(args[0], "=")
+
+	err := c.app.ConfigGetListSetter.SetConfiguration(keyValueParts[0], keyValueParts[1])
+	if err != nil {
+		return c.error(cmd, err)
 	}
+
 	return nil
 }
 
@@ -170,47 +289,562 @@ func (c *CobraHarness) SnapshotScenarioMetadata(cmd *cobra.Command, args []strin
 }
 
 func (c *CobraHarness) MetadataAdd(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	entries, err := parseMetadataArgs(args)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, c.app.MetadataEditor.SetMetadata(target, entries...))
+}
+
+// metadataTarget builds the target of a metadata command from its --actor,
+// --story, --scenario and --step flags, and any filters.
+func (c *CobraHarness) metadataTarget(cmd *cobra.Command) (specstack.MetadataTarget, error) {
 	storyName, scenarioName := c.parseStoryAndScenarioNames(
 		c.flagValueString(cmd, "story"),
 		c.flagValueString(cmd, "scenario"),
 	)
+	target := specstack.MetadataTarget{
+		Actor:    c.flagValueString(cmd, "actor"),
+		Story:    storyName,
+		Scenario: scenarioName,
+		Step:     c.flagValueString(cmd, "step"),
+	}
 
-	switch {
-	case scenarioName != "":
-		return c.errorOrNil(cmd, 1, c.addMetadataToScenario(scenarioName, storyName, args))
+	if target.Step != "" && target.Scenario == "" {
+		return target, errStepWithoutScenario
+	}
 
-	case storyName != "":
-		return c.errorOrNil(cmd, 1, c.addMetadataToStory(storyName, args))
+	var err error
+	if target.Scenario != "" {
+		target.Filters, err = c.scenarioFilters(cmd)
+	} else {
+		target.Filters, err = c.storyFilters(cmd)
+	}
+	return target, err
+}
+
+func (c *CobraHarness) MetadataSet(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	entries, err := c.metadataToSet(cmd, args)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, c.app.MetadataEditor.SetMetadata(target, entries...))
+}
+
+// metadataToSet reads the entries to set from the arguments, or from stdin in
+// the --format given when --stdin is set.
+func (c *CobraHarness) metadataToSet(cmd *cobra.Command, args []string) ([]*metadata.Entry, error) {
+	if stdin, _ := cmd.Flags().GetBool("stdin"); !stdin {
+		return parseMetadataArgs(args)
+	}
+
+	scanner, err := metadata.NewPrintScanner(c.flagValueString(cmd, "format"))
+	if err != nil {
+		return nil, err
+	}
+
+	scanned, err := scanner.Scan(c.stdin)
+	if err != nil {
+		return nil, err
+	}
+	return scannedEntries(scanned)
+}
+
+func (c *CobraHarness) MetadataExport(cmd *cobra.Command, args []string) error {
+	records, err := c.app.MetadataExportImporter.ExportMetadata()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printMetadataRecords(c.stdout, c.flagValueString(cmd, "format"), records))
+}
+
+// MetadataImport records the metadata in an exported file, and reports what
+// was, or with --dry-run would be, recorded.
+func (c *CobraHarness) MetadataImport(cmd *cobra.Command, args []string) error {
+	format := c.flagValueString(cmd, "format")
+	if format == "" {
+		format = exportFormatOf(args[0])
+	}
+
+	input := c.stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return c.error(cmd, err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	records, err := scanMetadataRecords(input, format)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	imports, err := c.app.MetadataExportImporter.ImportMetadata(records, dryRun)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	if err := printMetadataImports(c.stdout, imports, dryRun); err != nil {
+		return c.error(cmd, err)
+	}
+
+	failed := 0
+	for _, imported := range imports {
+		if imported.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return c.error(cmd, fmt.Errorf("%d records could not be imported, so nothing was recorded", failed))
+	}
+	return nil
+}
+
+func (c *CobraHarness) MetadataRemove(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, c.app.MetadataEditor.RemoveMetadata(target, args...))
+}
+
+// MetadataEdit opens the current metadata in the user's editor, then records
+// the values that were changed or added, and removes those that were deleted.
+func (c *CobraHarness) MetadataEdit(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	current, err := c.metadataEntries(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	printScanner, err := metadata.NewPrintScanner(c.flagValueString(cmd, "format"))
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	edited, err := c.editMetadata(printScanner, current)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	changed, removed := metadataChanges(current, edited)
+	if len(changed) > 0 {
+		if err := c.app.MetadataEditor.SetMetadata(target, changed...); err != nil {
+			return c.error(cmd, err)
+		}
+	}
+	if len(removed) > 0 {
+		if err := c.app.MetadataEditor.RemoveMetadata(target, removed...); err != nil {
+			return c.error(cmd, err)
+		}
 	}
 
-	return c.error(cmd, fmt.Errorf("specify a story or scenario"))
+	return nil
+}
+
+func (c *CobraHarness) editMetadata(
+	printScanner metadata.PrintScanner,
+	entries []*metadata.Entry,
+) ([]metadata.Entry, error) {
+	content := &bytes.Buffer{}
+	if err := printScanner.Print(content, entries); err != nil {
+		return nil, err
+	}
+
+	edited, err := editText(content.String(), c.stdin, c.stdout, c.stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	return printScanner.Scan(strings.NewReader(edited))
 }
 
 func (c *CobraHarness) MetadataList(cmd *cobra.Command, args []string) error {
-	var entries []*metadata.Entry
+	if history, _ := cmd.Flags().GetBool("history"); history {
+		return c.metadataHistory(cmd)
+	}
+
+	entries, err := c.metadataEntries(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	printer, err := metadata.NewPrintScanner(c.flagValueString(cmd, "format"))
+	if err != nil {
+		return c.error(cmd, err)
+	}
+	return printer.Print(c.stdout, c.metadataWrittenBy(cmd, entries))
+}
+
+func (c *CobraHarness) metadataHistory(cmd *cobra.Command) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	entries, err := c.app.MetadataEditor.GetMetadataHistory(target)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printMetadataHistory(c.stdout, c.metadataWrittenBy(cmd, entries)))
+}
+
+// metadataWrittenBy keeps the entries written by the author given with
+// --author, if any.
+func (c *CobraHarness) metadataWrittenBy(cmd *cobra.Command, entries []*metadata.Entry) []*metadata.Entry {
+	author, _ := cmd.Flags().GetString("author")
+	if author == "" {
+		return entries
+	}
+
+	written := []*metadata.Entry{}
+	for _, entry := range entries {
+		if entry.WrittenBy(author) {
+			written = append(written, entry)
+		}
+	}
+	return written
+}
+
+func (c *CobraHarness) metadataEntries(cmd *cobra.Command) ([]*metadata.Entry, error) {
 	storyName, scenarioName := c.parseStoryAndScenarioNames(
 		c.flagValueString(cmd, "story"),
 		c.flagValueString(cmd, "scenario"),
 	)
 
-	var err error
+	step := c.flagValueString(cmd, "step")
+
 	switch {
+	case c.flagValueString(cmd, "actor") != "":
+		return c.app.MetadataGetter.GetActorMetadata(c.flagValueString(cmd, "actor"))
+
+	case step != "" && scenarioName == "":
+		return nil, errStepWithoutScenario
+
+	case step != "":
+		filters, err := c.scenarioFilters(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return c.app.MetadataGetter.GetStepMetadata(step, scenarioName, storyName, filters...)
+
 	case scenarioName != "":
-		entries, err = c.app.MetadataGetAdder.GetScenarioMetadata(scenarioName, storyName)
+		filters, err := c.scenarioFilters(cmd)
 		if err != nil {
-			return c.error(cmd, err)
+			return nil, err
 		}
+		return c.app.MetadataGetter.GetScenarioMetadata(scenarioName, storyName, filters...)
+
 	case storyName != "":
-		entries, err = c.app.MetadataGetAdder.GetStoryMetadata(storyName)
+		filters, err := c.storyFilters(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return c.app.MetadataGetter.GetStoryMetadata(storyName, filters...)
+	}
+
+	return nil, fmt.Errorf("specify a story, scenario or actor")
+}
+
+func (c *CobraHarness) Actors(cmd *cobra.Command, args []string) error {
+	actors, err := c.app.ActorLister.ListActors()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printActors(c.stdout, actors))
+}
+
+func (c *CobraHarness) IDsAssign(cmd *cobra.Command, args []string) error {
+	// The IDs have been assigned even when pushing the moved metadata only
+	// warns, so they're listed before the warning.
+	assignments, err := c.app.ScenarioIDAssigner.AssignScenarioIDs()
+	if err != nil && !errors.IsWarning(err) {
+		return c.error(cmd, err)
+	}
+
+	if printErr := printIDAssignments(c.stdout, assignments); printErr != nil {
+		return c.error(cmd, printErr)
+	}
+	return c.errorOrNil(cmd, 1, err)
+}
+
+func (c *CobraHarness) IDsCheck(cmd *cobra.Command, args []string) error {
+	if err := c.app.ScenarioIDAssigner.CheckScenarioIDs(); err != nil {
+		return c.error(cmd, err)
+	}
+
+	_, err := fmt.Fprintln(c.stdout, "No duplicate scenario IDs")
+	return c.errorOrNil(cmd, 1, err)
+}
+
+func (c *CobraHarness) MetadataCheck(cmd *cobra.Command, args []string) error {
+	violations, err := c.app.MetadataChecker.CheckMetadata()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	if err := printMetadataViolations(c.stdout, violations); err != nil {
+		return c.error(cmd, err)
+	}
+
+	if len(violations) > 0 {
+		return c.error(cmd, fmt.Errorf("%d objects break the metadata schema", len(violations)))
+	}
+	return nil
+}
+
+// MetadataFind lists the stories or scenarios whose current metadata matches
+// every predicate given as an argument.
+func (c *CobraHarness) MetadataFind(cmd *cobra.Command, args []string) error {
+	predicates := []*metadata.Predicate{}
+	for _, arg := range args {
+		predicate, err := metadata.ParsePredicate(arg)
 		if err != nil {
 			return c.error(cmd, err)
 		}
+		predicates = append(predicates, predicate)
+	}
+
+	var (
+		results []queryResult
+		err     error
+	)
+	switch c.flagValueString(cmd, "type") {
+	case "scenario":
+		results, err = c.listScenarios(cmd, c.app.MetadataFinder.MetadataScenarioFilters(predicates...))
+	case "story":
+		results, err = c.listStories(cmd, c.app.MetadataFinder.MetadataStoryFilters(predicates...))
+	default:
+		err = fmt.Errorf("type must be one of story, scenario")
+	}
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.printQueryResults(cmd, c.flagValueString(cmd, "format"), results)
+}
+
+func (c *CobraHarness) Query(cmd *cobra.Command, args []string) error {
+	expression := strings.Join(args, " ")
+	format := c.flagValueString(cmd, "format")
+
+	var (
+		results []queryResult
+		err     error
+	)
+	switch c.flagValueString(cmd, "type") {
+	case "scenario":
+		results, err = c.queryScenarios(cmd, expression)
+	case "story":
+		results, err = c.queryStories(cmd, expression)
 	default:
-		return c.error(cmd, fmt.Errorf("specify a story or scenario"))
+		err = fmt.Errorf("type must be one of story, scenario")
+	}
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.printQueryResults(cmd, format, results)
+}
+
+func (c *CobraHarness) printQueryResults(cmd *cobra.Command, format string, results []queryResult) error {
+	switch format {
+	case "json":
+		return c.errorOrNil(cmd, 1, printQueryResultsJSON(c.stdout, results))
+	case "table":
+		return c.errorOrNil(cmd, 1, printQueryResultsTable(c.stdout, results))
+	}
+
+	return c.error(cmd, fmt.Errorf("format must be one of table, json"))
+}
+
+func (c *CobraHarness) queryStories(cmd *cobra.Command, expression string) ([]queryResult, error) {
+	queryFilters, err := c.app.SpecificationQuerier.StoryFilters(expression)
+	if err != nil {
+		return nil, err
+	}
+	return c.listStories(cmd, queryFilters)
+}
+
+// listStories lists the stories that match the --tags and --query flags and
+// the given filters.
+func (c *CobraHarness) listStories(
+	cmd *cobra.Command,
+	queryFilters []specification.QueryMapFunc,
+) ([]queryResult, error) {
+	filters, err := c.storyFilters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	stories, err := c.app.SpecificationQuerier.QueryStories(append(filters, queryFilters...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]queryResult, len(stories))
+	for i, story := range stories {
+		results[i] = newStoryQueryResult(story)
+	}
+	return results, nil
+}
+
+func (c *CobraHarness) queryScenarios(cmd *cobra.Command, expression string) ([]queryResult, error) {
+	queryFilters, err := c.app.SpecificationQuerier.ScenarioFilters(expression)
+	if err != nil {
+		return nil, err
+	}
+	return c.listScenarios(cmd, queryFilters)
+}
+
+// listScenarios lists the scenarios that match the --tags and --query flags
+// and the given filters.
+func (c *CobraHarness) listScenarios(
+	cmd *cobra.Command,
+	queryFilters []specification.QueryMapFunc,
+) ([]queryResult, error) {
+	filters, err := c.scenarioFilters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	scenarios, err := c.app.SpecificationQuerier.QueryScenarios(append(filters, queryFilters...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]queryResult, len(scenarios))
+	for i, scenario := range scenarios {
+		results[i] = newScenarioQueryResult(scenario)
+	}
+	return results, nil
+}
+
+func (c *CobraHarness) Diff(cmd *cobra.Command, args []string) error {
+	from, to := args[0], ""
+	if len(args) > 1 {
+		to = args[1]
+	}
+
+	changes, err := c.app.SpecificationDiffer.DiffSpecification(from, to)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	switch c.flagValueString(cmd, "format") {
+	case "text":
+		return c.errorOrNil(cmd, 1, printDiffText(c.stdout, changes))
+	case "json":
+		return c.errorOrNil(cmd, 1, printDiffJSON(c.stdout, changes))
+	case "markdown":
+		return c.errorOrNil(cmd, 1, printDiffMarkdown(c.stdout, changes))
+	}
+
+	return c.error(cmd, fmt.Errorf("format must be one of text, json, markdown"))
+}
+
+func (c *CobraHarness) Discuss(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	// The thread is recorded even when pushing it only gives a warning, so
+	// its ID is printed before any error.
+	thread, err := c.app.Discusser.StartDiscussion(target, args[0])
+	if thread != "" {
+		if _, printErr := fmt.Fprintf(c.stdout, "Started thread %s\n", thread); printErr != nil {
+			return c.error(cmd, printErr)
+		}
+	}
+
+	return c.errorOrNil(cmd, 1, err)
+}
+
+func (c *CobraHarness) DiscussReply(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, c.app.Discusser.ReplyToDiscussion(target, args[0], args[1]))
+}
+
+func (c *CobraHarness) DiscussResolve(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, c.app.Discusser.ResolveDiscussion(target, args[0]))
+}
+
+func (c *CobraHarness) DiscussReopen(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, c.app.Discusser.ReopenDiscussion(target, args[0]))
+}
+
+func (c *CobraHarness) DiscussList(cmd *cobra.Command, args []string) error {
+	target, err := c.metadataTarget(cmd)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	discussions, err := c.app.Discusser.ListDiscussions(target)
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	return c.errorOrNil(cmd, 1, printDiscussions(c.stdout, discussions, all))
+}
+
+func (c *CobraHarness) Progress(cmd *cobra.Command, args []string) error {
+	report, err := c.app.ProgressTracker.Progress()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printProgress(c.stdout, report))
+}
+
+func (c *CobraHarness) ProgressSet(cmd *cobra.Command, args []string) error {
+	storyName, scenarioName := c.parseStoryAndScenarioNames(c.flagValueString(cmd, "story"), args[0])
+
+	filters, err := c.scenarioFilters(cmd)
+	if err != nil {
+		return c.error(cmd, err)
 	}
 
-	printer := metadata.NewPlaintextPrintscanner()
-	return printer.Print(c.stdout, entries)
+	target := specstack.MetadataTarget{Story: storyName, Scenario: scenarioName, Filters: filters}
+	return c.errorOrNil(cmd, 1, c.app.ProgressTracker.SetProgress(target, args[1]))
 }
 
 func (c *CobraHarness) GitHookExec(cmd *cobra.Command, args []string) error {
@@ -228,8 +862,57 @@ func (c *CobraHarness) GitHookExec(cmd *cobra.Command, args []string) error {
 	return c.errorWithReturnCode(cmd, 1, fmt.Errorf("invalid hook name : %s", args[0]))
 }
 
+func (c *CobraHarness) HooksInstall(cmd *cobra.Command, args []string) error {
+	return c.printHookStatuses(cmd, c.app.HookManager.InstallHooks)
+}
+
+func (c *CobraHarness) HooksUninstall(cmd *cobra.Command, args []string) error {
+	return c.printHookStatuses(cmd, c.app.HookManager.UninstallHooks)
+}
+
+func (c *CobraHarness) HooksStatus(cmd *cobra.Command, args []string) error {
+	return c.printHookStatuses(cmd, c.app.HookManager.HookStatuses)
+}
+
+func (c *CobraHarness) printHookStatuses(cmd *cobra.Command, statuses func() ([]repository.HookStatus, error)) error {
+	hooks, err := statuses()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printHookStatuses(c.stdout, hooks))
+}
+
 func (c *CobraHarness) Pull(cmd *cobra.Command, args []string) error {
-	return c.errorOrNil(cmd, 1, c.app.PushPuller.Pull())
+	merge, err := c.app.PushPuller.Pull()
+	if err != nil {
+		return c.errorWithReturnCode(cmd, 1, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printMetadataMerge(c.stdout, merge))
+}
+
+func (c *CobraHarness) Status(cmd *cobra.Command, args []string) error {
+	pending, err := c.app.PushQueuer.PendingPushes()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	remote, err := c.app.ConfigGetListSetter.GetConfiguration("project.remote")
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	if err := printPendingPushes(c.stdout, remote, pending); err != nil {
+		return c.error(cmd, err)
+	}
+
+	hooks, err := c.app.HookManager.HookStatuses()
+	if err != nil {
+		return c.error(cmd, err)
+	}
+
+	return c.errorOrNil(cmd, 1, printHooksSummary(c.stdout, hooks))
 }
 
 func (c *CobraHarness) Push(cmd *cobra.Command, args []string) error {
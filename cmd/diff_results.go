@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/endiangroup/specstack/specification"
+)
+
+var diffMarkdownSections = []struct {
+	changeType specification.ScenarioChangeType
+	heading    string
+}{
+	{specification.ScenarioAdded, "Added"},
+	{specification.ScenarioRemoved, "Removed"},
+	{specification.ScenarioModified, "Modified"},
+	{specification.ScenarioRenamed, "Renamed"},
+	{specification.ScenarioMoved, "Moved"},
+}
+
+type diffLine struct {
+	Operation string `json:"op"`
+	Text      string `json:"text"`
+}
+
+type diffResult struct {
+	Type  specification.ScenarioChangeType `json:"type"`
+	From  *queryResult                     `json:"from,omitempty"`
+	To    *queryResult                     `json:"to,omitempty"`
+	Steps []diffLine                       `json:"steps,omitempty"`
+}
+
+func newDiffResult(change specification.ScenarioChange) diffResult {
+	result := diffResult{Type: change.Type}
+
+	if change.From != nil {
+		from := newScenarioQueryResult(change.From)
+		result.From = &from
+	}
+	if change.To != nil {
+		to := newScenarioQueryResult(change.To)
+		result.To = &to
+	}
+	for _, line := range change.Steps {
+		result.Steps = append(result.Steps, diffLine{line.Operation, line.Text})
+	}
+
+	return result
+}
+
+func newDiffResults(changes []specification.ScenarioChange) []diffResult {
+	results := make([]diffResult, len(changes))
+	for i, change := range changes {
+		results[i] = newDiffResult(change)
+	}
+	return results
+}
+
+// name returns the scenario name, or "old -> new" if it was renamed.
+func (r diffResult) name() string {
+	switch {
+	case r.From == nil:
+		return r.To.Scenario
+	case r.To == nil, r.From.Scenario == r.To.Scenario:
+		return r.From.Scenario
+	}
+	return fmt.Sprintf("%s -> %s", r.From.Scenario, r.To.Scenario)
+}
+
+// location returns where the scenario is, or "old -> new" if it moved file.
+func (r diffResult) location() string {
+	switch {
+	case r.From == nil:
+		return r.To.location()
+	case r.To == nil, r.From.File == r.To.File:
+		return r.From.location()
+	}
+	return fmt.Sprintf("%s -> %s", r.From.location(), r.To.location())
+}
+
+func printDiffJSON(w io.Writer, changes []specification.ScenarioChange) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(newDiffResults(changes))
+}
+
+func printDiffText(w io.Writer, changes []specification.ScenarioChange) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No scenarios changed")
+		return err
+	}
+
+	for _, r := range newDiffResults(changes) {
+		if _, err := fmt.Fprintf(w, "%s: %s (%s)\n", r.Type, r.name(), r.location()); err != nil {
+			return err
+		}
+		for _, line := range r.Steps {
+			if _, err := fmt.Fprintf(w, "    %s %s\n", line.Operation, line.Text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func printDiffMarkdown(w io.Writer, changes []specification.ScenarioChange) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No scenarios changed.")
+		return err
+	}
+
+	results := newDiffResults(changes)
+	sections := []string{}
+	for _, section := range diffMarkdownSections {
+		items := []string{}
+		for _, r := range results {
+			if r.Type == section.changeType {
+				items = append(items, diffMarkdownItem(r))
+			}
+		}
+		if len(items) > 0 {
+			sections = append(sections, fmt.Sprintf("### %s\n\n%s", section.heading, strings.Join(items, "")))
+		}
+	}
+
+	_, err := fmt.Fprint(w, strings.Join(sections, "\n"))
+	return err
+}
+
+func diffMarkdownItem(r diffResult) string {
+	item := fmt.Sprintf("- **%s** `%s`\n", r.name(), r.location())
+	if len(r.Steps) == 0 {
+		return item
+	}
+
+	lines := []string{"  ```diff"}
+	for _, line := range r.Steps {
+		lines = append(lines, fmt.Sprintf("  %s %s", line.Operation, line.Text))
+	}
+	lines = append(lines, "  ```")
+
+	return item + "\n" + strings.Join(lines, "\n") + "\n\n"
+}
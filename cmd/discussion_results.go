@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/endiangroup/specstack"
+	"github.com/endiangroup/specstack/discussion"
+)
+
+const discussionTimeFormat = "2006-01-02 15:04"
+
+// printDiscussions prints the open threads of each discussion, or with all
+// the resolved ones too, leaving out discussions with nothing to show.
+func printDiscussions(w io.Writer, discussions []specstack.Discussion, all bool) error {
+	printed := false
+	for _, d := range discussions {
+		threads := []*discussion.Thread{}
+		for _, thread := range d.Threads {
+			if all || !thread.Resolved {
+				threads = append(threads, thread)
+			}
+		}
+		if len(threads) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %s\n", d.Kind, d.Label); err != nil {
+			return err
+		}
+		for _, thread := range threads {
+			if err := printThread(w, thread); err != nil {
+				return err
+			}
+		}
+		printed = true
+	}
+
+	if !printed {
+		_, err := fmt.Fprintln(w, "No open discussions")
+		return err
+	}
+	return nil
+}
+
+func printThread(w io.Writer, thread *discussion.Thread) error {
+	state := "open"
+	if thread.Resolved {
+		state = "resolved"
+	}
+	if _, err := fmt.Fprintf(w, "  %s (%s)\n", thread.ID, state); err != nil {
+		return err
+	}
+
+	for _, post := range thread.Posts {
+		by := fmt.Sprintf("%s at %s", post.Entry.AuthorName(), post.Entry.CreatedAt.Local().Format(discussionTimeFormat))
+
+		var err error
+		switch post.Action {
+		case discussion.ActionResolve:
+			_, err = fmt.Fprintf(w, "    %s resolved the thread\n", by)
+		case discussion.ActionReopen:
+			_, err = fmt.Fprintf(w, "    %s reopened the thread\n", by)
+		default:
+			_, err = fmt.Fprintf(w, "    %s: %s\n", by, post.Message)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
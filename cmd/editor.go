@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+const defaultEditor = "vi"
+
+// editText opens the text in the editor named by $EDITOR and returns the
+// result once the editor exits.
+func editText(text string, stdin io.Reader, stdout, stderr io.Writer) (string, error) {
+	file, err := ioutil.TempFile("", "spec-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = defaultEditor
+	}
+
+	// Run through the shell so that $EDITOR can include arguments.
+	command := exec.Command("sh", "-c", editor+` "$1"`, "sh", file.Name())
+	command.Stdin, command.Stdout, command.Stderr = stdin, stdout, stderr
+	if err := command.Run(); err != nil {
+		return "", err
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	return string(edited), err
+}
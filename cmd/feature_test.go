@@ -60,13 +60,26 @@ func newTestHarness() *testHarness {
 		th.stderr,
 	)
 	app := specstack.Application{
-		ConfigAsserter:      developer,
-		ConfigGetListSetter: developer,
-		MetadataGetAdder:    developer,
-		MetadataTransferer:  developer,
-		PushPuller:          developer,
-		RepoHooker:          developer,
-		Repository:          git,
+		ConfigAsserter:         developer,
+		ConfigGetListSetter:    developer,
+		MetadataGetter:         developer,
+		MetadataEditor:         developer,
+		SpecificationQuerier:   developer,
+		SpecificationDiffer:    developer,
+		ActorLister:            developer,
+		ScenarioIDAssigner:     developer,
+		MetadataChecker:        developer,
+		MetadataFinder:         developer,
+		MetadataExportImporter: developer,
+		Discusser:              developer,
+		ProgressTracker:        developer,
+		RevisionSelector:       developer,
+		MetadataTransferer:     developer,
+		PushPuller:             developer,
+		PushQueuer:             developer,
+		RepoHooker:             developer,
+		HookManager:            developer,
+		Repository:             git,
 	}
 
 	th.cobra = WireUpCobraHarness(NewCobraHarness(&app, th.stdin, th.stdout, th.stderr))
@@ -99,6 +112,10 @@ func (t *testHarness) ScenarioCleanup(_ interface{}, _ error) {
 		t.gitServer.Close()
 	}
 
+	if err := os.Unsetenv("EDITOR"); err != nil {
+		panic(err)
+	}
+
 	*t = *newTestHarness()
 }
 
@@ -145,6 +162,7 @@ func (t *testHarness) iRunTheCommand(cmd string) error {
 		index++
 	}
 
+	t.exitCode = 0
 	t.cobra.SetArgs(processed)
 	err := t.cobra.Execute()
 	if err != nil {
@@ -225,6 +243,28 @@ func (t *testHarness) iShouldSeeTheFollowing(output *gherkin.DocString) error {
 	return t.iShouldSeeNoErrors()
 }
 
+func (t *testHarness) iShouldSeeTheFollowingProblems(output *gherkin.DocString) error {
+	for _, line := range strings.Split(output.Content, "\n") {
+		if !assert.Contains(t, t.stdout.String(), line) {
+			return t.AssertError()
+		}
+	}
+
+	if !assert.True(t, t.exitCode > 0, "Zero exit coded returned, expected > 0") {
+		return t.AssertError()
+	}
+
+	return nil
+}
+
+func (t *testHarness) iShouldNotSee(output string) error {
+	if !assert.NotContains(t, t.stdout.String(), output) {
+		return t.AssertError()
+	}
+
+	return nil
+}
+
 func (t *testHarness) iShouldSeeSomeConfigurationKeysAndValues() error {
 	if !assert.True(t, len(strings.Split(t.stdout.String(), "\n")) > 0, "Nothing outputted, expected some lines") {
 		return t.AssertError()
@@ -452,6 +492,30 @@ func (t *testHarness) iMakeACommit() error {
 	return t.RunGitCommand("commit", "-m", "iMakeACommit")
 }
 
+func (t *testHarness) iTagTheCommitAs(tag string) error {
+	return t.RunGitCommand("tag", tag)
+}
+
+func (t *testHarness) iRunTheCommandWithTheFollowingInput(cmd string, input *gherkin.DocString) error {
+	t.stdin.Reset()
+	t.stdin.WriteString(input.Content + "\n")
+	return t.iRunTheCommand(cmd)
+}
+
+// iRunTheCommandOnTheThreadIStarted runs a command with <thread> replaced by
+// the ID of the last discussion thread started.
+func (t *testHarness) iRunTheCommandOnTheThreadIStarted(cmd string) error {
+	started := regexp.MustCompile(`Started thread (\w+)`).FindAllStringSubmatch(t.stdout.String(), -1)
+	if len(started) == 0 {
+		return fmt.Errorf("no thread was started")
+	}
+	return t.iRunTheCommand(strings.Replace(cmd, "<thread>", started[len(started)-1][1], -1))
+}
+
+func (t *testHarness) myEditorReplacesWith(from, to string) error {
+	return os.Setenv("EDITOR", fmt.Sprintf("sed -i 's/%s/%s/'", from, to))
+}
+
 func (t *testHarness) iHaveAProperlyConfiguredProjectDirectory() error {
 	if err := t.iHaveAnEmptyDirectory(); err != nil {
 		return err
@@ -703,6 +767,10 @@ func (t *testHarness) RunGitCommand(args ...string) error {
 	return err
 }
 
+func (t *testHarness) gitRunsHooksFrom(path string) error {
+	return t.RunGitCommand("config", "core.hooksPath", path)
+}
+
 func (t *testHarness) RunGitCommands(args ...[]string) error {
 	for _, arg := range args {
 		if err := t.RunGitCommand(arg...); err != nil {
@@ -745,11 +813,15 @@ func FeatureContext(s *godog.Suite) {
 	s.Step(`^I have an empty directory$`, th.iHaveAnEmptyDirectory)
 	s.Step(`^I have a project directory$`, th.iHaveAProjectDirectory)
 	s.Step(`^I run "([^"]*)"$`, th.iRunTheCommand)
+	s.Step(`^I run "([^"]*)" with the following input:$`, th.iRunTheCommandWithTheFollowingInput)
+	s.Step(`^I run "([^"]*)" on the thread I started$`, th.iRunTheCommandOnTheThreadIStarted)
 	s.Step(`^I should see an error message informing me "([^"]*)"$`, th.iShouldSeeAnErrorMessageInformingMe)
 	s.Step(`^I should see a warning message informing me "([^"]*)"$`, th.iShouldSeeAWarningMessageInformingMe)
 	s.Step(`^I should see a helpful suggestion informing me "([^"]*)"$`, th.iShouldSeeAHelpfulSuggestionInformingMe)
 	s.Step(`^I have initialised git$`, th.iHaveInitialisedGit)
 	s.Step(`^I should see the following:$`, th.iShouldSeeTheFollowing)
+	s.Step(`^I should see the following problems:$`, th.iShouldSeeTheFollowingProblems)
+	s.Step(`^I should not see "([^"]*)"$`, th.iShouldNotSee)
 	s.Step(`^I should see some configuration keys and values$`, th.iShouldSeeSomeConfigurationKeysAndValues)
 	s.Step(`^The config key "([^"]*)" should equal "([^"]*)"$`, th.theConfigKeyShouldEqual)
 	s.Step(`^I have no user details$`, th.iHaveNoUserDetails)
@@ -757,6 +829,7 @@ func FeatureContext(s *godog.Suite) {
 	s.Step(`^I have set the git user email to "([^"]*)"$`, th.iHaveSetTheGitUserEmailTo)
 	s.Step(`^I have set my user details$`, th.iHaveSetMyUserDetails)
 	s.Step(`^I have a file called "([^"]*)" with the following content:$`, th.iHaveAFileCalledWithTheFollowingContent)
+	s.Step(`^git runs hooks from "([^"]*)"$`, th.gitRunsHooksFrom)
 	s.Step(`^I have a story called "([^"]*)"$`, th.iHaveAStoryCalled)
 	s.Step(`^I have a story called "([^"]*)" in my spec with the following metadata:$`, th.iHaveAStoryCalledInMySpecWithTheFollowingMetadata)
 	s.Step(`^My story "([^"]*)" has a scenario called "([^"]*)" with the following metadata:$`, th.myStoryHasAScenarioCalledWithTheFollowingMetadata)
@@ -777,6 +850,8 @@ func FeatureContext(s *godog.Suite) {
 	s.Step(`^I run a git pull$`, th.iRunAGitPull)
 	s.Step(`^I run a git push$`, th.iRunAGitPush)
 	s.Step(`^I make a commit$`, th.iMakeACommit)
+	s.Step(`^I tag the commit as "([^"]*)"$`, th.iTagTheCommitAs)
+	s.Step(`^my editor replaces "([^"]*)" with "([^"]*)"$`, th.myEditorReplacesWith)
 	s.Step(`^I have set the pulling mode to automatic$`, th.iHaveSetThePullingModeToAutomatic)
 	s.Step(`^I have set the pushing mode to semi-automatic$`, th.iHaveSetThePushingModeToSemiautomatic)
 	s.Step(`^I have set the pushing mode to automatic$`, th.iHaveSetThePushingModeToAutomatic)
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/endiangroup/specstack/repository"
+)
+
+var hookStateDescriptions = map[repository.HookState]string{
+	repository.HookMissing:   "not installed",
+	repository.HookOther:     "not installed, another hook is there",
+	repository.HookInstalled: "installed",
+	repository.HookAdded:     "added to the existing hook",
+	repository.HookChained:   "chained before the existing hook",
+	repository.HookLegacy:    "installed by an earlier version",
+}
+
+// printHookStatuses prints the hooks directory, then whether each hook runs
+// spec.
+func printHookStatuses(w io.Writer, hooks []repository.HookStatus) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "Hooks directory: %s\n", filepath.Dir(hooks[0].Path)); err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "HOOK\tSTATE\n")
+	for _, hook := range hooks {
+		fmt.Fprintf(table, "%s\t%s\n", hook.Name, hookStateDescriptions[hook.State])
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if hook.State == repository.HookOther {
+			_, err := fmt.Fprintln(w, "Run spec hooks install to chain into the existing hooks")
+			return err
+		}
+	}
+	return nil
+}
+
+// printHooksSummary points to spec hooks status when some hooks don't run
+// spec.
+func printHooksSummary(w io.Writer, hooks []repository.HookStatus) error {
+	missing := 0
+	for _, hook := range hooks {
+		if !hook.Runs() {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	verb := "don't"
+	if missing == 1 {
+		verb = "doesn't"
+	}
+
+	_, err := fmt.Fprintf(w, "%d of %d git hooks %s run spec, see spec hooks status\n", missing, len(hooks), verb)
+	return err
+}
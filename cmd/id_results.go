@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/endiangroup/specstack/specification"
+)
+
+func printIDAssignments(w io.Writer, assignments []specification.IDAssignment) error {
+	if len(assignments) == 0 {
+		_, err := fmt.Fprintln(w, "All scenarios have IDs")
+		return err
+	}
+
+	for _, assignment := range assignments {
+		result := newScenarioQueryResult(assignment.Scenario)
+		if _, err := fmt.Fprintf(w, "%s%s: %s (%s)\n",
+			specification.IDTagPrefix, assignment.ID, result.Scenario, result.location()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/endiangroup/specstack"
+	"github.com/endiangroup/specstack/metadata"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The formats metadata can be exported and imported in.
+const (
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+	exportFormatYAML = "yaml"
+)
+
+var exportFormats = []string{exportFormatJSON, exportFormatCSV, exportFormatYAML}
+
+// exportCSVHeader heads the columns of exported CSV, which has a row for each
+// entry, or an empty one for an object without metadata.
+var exportCSVHeader = []string{"kind", "id", "story", "name", "file", "line", "hash", "key", "value", "json"}
+
+// exportYAMLIndent indents the metadata of each exported record in YAML.
+const exportYAMLIndent = "    "
+
+func errUnknownExportFormat(format string) error {
+	return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(exportFormats, ", "))
+}
+
+// exportFormatOf guesses the format of a file from its extension.
+func exportFormatOf(file string) string {
+	switch {
+	case strings.HasSuffix(file, ".csv"):
+		return exportFormatCSV
+	case strings.HasSuffix(file, ".yaml"), strings.HasSuffix(file, ".yml"):
+		return exportFormatYAML
+	}
+	return exportFormatJSON
+}
+
+func printMetadataRecords(w io.Writer, format string, records []*specstack.MetadataRecord) error {
+	switch format {
+	case exportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case exportFormatCSV:
+		return printMetadataRecordsCSV(w, records)
+	case exportFormatYAML:
+		return printMetadataRecordsYAML(w, records)
+	}
+	return errUnknownExportFormat(format)
+}
+
+func scanMetadataRecords(r io.Reader, format string) ([]*specstack.MetadataRecord, error) {
+	switch format {
+	case exportFormatJSON:
+		records := []*specstack.MetadataRecord{}
+		return records, json.NewDecoder(r).Decode(&records)
+	case exportFormatCSV:
+		return scanMetadataRecordsCSV(r)
+	case exportFormatYAML:
+		return scanMetadataRecordsYAML(r)
+	}
+	return nil, errUnknownExportFormat(format)
+}
+
+func printMetadataRecordsCSV(w io.Writer, records []*specstack.MetadataRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		object := []string{
+			record.Kind, record.ID, record.Story, record.Name, record.File, strconv.Itoa(record.Line), record.Hash,
+		}
+		if len(record.Metadata) == 0 {
+			if err := writer.Write(append(object, "", "", "")); err != nil {
+				return err
+			}
+		}
+		for _, entry := range record.Metadata {
+			row := append(append([]string{}, object...), entry.Name, entry.Value, strconv.FormatBool(entry.Typed()))
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// scanMetadataRecordsCSV reads CSV rows back into records, gathering the rows
+// of each object wherever they are, in case they were sorted.
+func scanMetadataRecordsCSV(r io.Reader) ([]*specstack.MetadataRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(exportCSVHeader)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []*specstack.MetadataRecord{}
+	objects := map[string]*specstack.MetadataRecord{}
+	for i, row := range rows {
+		if i == 0 {
+			continue
+		}
+
+		object := strings.Join(row[:7], "\x00")
+		record, ok := objects[object]
+		if !ok {
+			line, err := strconv.Atoi(row[5])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid line %q", i+1, row[5])
+			}
+			record = &specstack.MetadataRecord{
+				Kind: row[0], ID: row[1], Story: row[2], Name: row[3], File: row[4], Line: line, Hash: row[6],
+			}
+			objects[object] = record
+			records = append(records, record)
+		}
+
+		if entry, err := scanMetadataCSVEntry(row[7], row[8], row[9]); err != nil {
+			return nil, fmt.Errorf("row %d: %s", i+1, err)
+		} else if entry != nil {
+			record.Metadata = append(record.Metadata, entry)
+		}
+	}
+
+	return records, nil
+}
+
+func scanMetadataCSVEntry(key, value, typed string) (*metadata.Entry, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if isJSON, _ := strconv.ParseBool(typed); isJSON {
+		return metadata.NewTypedKeyValue(key, value)
+	}
+	return metadata.NewKeyValue(key, value), nil
+}
+
+// printMetadataRecordsYAML prints records as a YAML sequence, with the
+// metadata of each in the layout of metadata list --format yaml.
+func printMetadataRecordsYAML(w io.Writer, records []*specstack.MetadataRecord) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	for _, record := range records {
+		entries := &bytes.Buffer{}
+		if len(record.Metadata) > 0 {
+			if err := metadata.NewYAMLPrintScanner().Print(entries, record.Metadata); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "- kind: %s\n  id: %s\n  story: %s\n  name: %s\n  file: %s\n  line: %d\n  hash: %s\n",
+			strconv.Quote(record.Kind), strconv.Quote(record.ID), strconv.Quote(record.Story),
+			strconv.Quote(record.Name), strconv.Quote(record.File), record.Line, strconv.Quote(record.Hash),
+		); err != nil {
+			return err
+		}
+
+		if entries.Len() == 0 {
+			if _, err := fmt.Fprintln(w, "  metadata: []"); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(w, "  metadata:"); err != nil {
+			return err
+		}
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(entries.String(), "\n"), "\n") {
+			if _, err := fmt.Fprint(w, exportYAMLIndent+line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A yamlRecord is a record as it is laid out in YAML.
+type yamlRecord struct {
+	Kind     string               `yaml:"kind"`
+	ID       string               `yaml:"id"`
+	Story    string               `yaml:"story"`
+	Name     string               `yaml:"name"`
+	File     string               `yaml:"file"`
+	Line     int                  `yaml:"line"`
+	Hash     string               `yaml:"hash"`
+	Metadata []metadata.YAMLEntry `yaml:"metadata"`
+}
+
+// scanMetadataRecordsYAML reads records from YAML laid out as
+// printMetadataRecordsYAML prints them.
+func scanMetadataRecordsYAML(r io.Reader) ([]*specstack.MetadataRecord, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned := []yamlRecord{}
+	if err := yaml.UnmarshalStrict(content, &scanned); err != nil {
+		return nil, err
+	}
+
+	records := []*specstack.MetadataRecord{}
+	for _, y := range scanned {
+		record := &specstack.MetadataRecord{
+			Kind: y.Kind, ID: y.ID, Story: y.Story, Name: y.Name, File: y.File, Line: y.Line, Hash: y.Hash,
+		}
+		for _, yamlEntry := range y.Metadata {
+			entry, err := yamlEntry.Entry()
+			if err != nil {
+				return nil, err
+			}
+			record.Metadata = append(record.Metadata, &entry)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func printMetadataImports(w io.Writer, imports []specstack.MetadataImport, dryRun bool) error {
+	for _, imported := range imports {
+		if _, err := fmt.Fprintf(w, "%s %s: %s\n",
+			imported.Record.Kind, imported.Record.Label(), metadataImportOutcome(imported, dryRun)); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		_, err := fmt.Fprintln(w, "Dry run, no metadata was imported")
+		return err
+	}
+	return nil
+}
+
+func metadataImportOutcome(imported specstack.MetadataImport, dryRun bool) string {
+	if imported.Err != nil {
+		return imported.Err.Error()
+	}
+
+	outcome := "no changes"
+	if len(imported.Changed) > 0 {
+		names := make([]string, len(imported.Changed))
+		for i, entry := range imported.Changed {
+			names[i] = entry.Name
+		}
+		outcome = "set " + strings.Join(names, ", ")
+		if dryRun {
+			outcome = "would " + outcome
+		}
+	}
+
+	matched := fmt.Sprintf("matched by %s", imported.Match)
+	if imported.Match == specstack.MatchFuzzy {
+		matched = fmt.Sprintf("matched %s by %s", imported.Target.Label(), imported.Match)
+	}
+	return fmt.Sprintf("%s (%s)", outcome, matched)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/endiangroup/specstack"
+	"github.com/endiangroup/specstack/errors"
+	"github.com/endiangroup/specstack/metadata"
+)
+
+const typedValueSeparator = ":="
+
+// parseMetadataArg turns a key=value argument into an entry, or a
+// key:=<json> argument into a typed entry.
+func parseMetadataArg(arg string) (*metadata.Entry, error) {
+	if kv := strings.SplitN(arg, typedValueSeparator, 2); len(kv) == 2 && !strings.Contains(kv[0], "=") {
+		entry, err := metadata.NewTypedKeyValue(kv[0], kv[1])
+		if err != nil {
+			return nil, &errors.ValidationError{E: err}
+		}
+		return entry, nil
+	}
+
+	kv := strings.SplitN(arg, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return nil, &errors.ValidationError{
+			E: errors.New("invalid argument format, expected: key=value or key:=<json>"),
+		}
+	}
+	return metadata.NewKeyValue(kv[0], kv[1]), nil
+}
+
+func parseMetadataArgs(args []string) ([]*metadata.Entry, error) {
+	entries := []*metadata.Entry{}
+	for _, arg := range args {
+		entry, err := parseMetadataArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// scannedEntries turns scanned metadata into entries to record, keeping typed
+// values typed. Removed entries are left out, as there is nothing to set.
+func scannedEntries(scanned []metadata.Entry) ([]*metadata.Entry, error) {
+	entries := []*metadata.Entry{}
+	for _, entry := range scanned {
+		switch {
+		case entry.Deleted:
+		case entry.Typed():
+			typed, err := metadata.NewTypedKeyValue(entry.Name, string(entry.Data))
+			if err != nil {
+				return nil, &errors.ValidationError{E: err}
+			}
+			entries = append(entries, typed)
+		default:
+			entries = append(entries, metadata.NewKeyValue(entry.Name, entry.Value))
+		}
+	}
+	return entries, nil
+}
+
+func printMetadataViolations(w io.Writer, violations []specstack.MetadataViolation) error {
+	if len(violations) == 0 {
+		_, err := fmt.Fprintln(w, "All metadata matches the schema")
+		return err
+	}
+
+	for _, violation := range violations {
+		if _, err := fmt.Fprintf(w, "%s %s (%s): %s\n",
+			violation.Kind, violation.Name, violation.Location, violation.Err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printMetadataHistory(w io.Writer, entries []*metadata.Entry) error {
+	for _, entry := range entries {
+		line := fmt.Sprintf("%s: %s", entry.Name, entry.Value)
+		if entry.Deleted {
+			line = fmt.Sprintf("%s (removed)", entry.Name)
+		}
+		if entry.Author != "" {
+			line += fmt.Sprintf("  by %s", entry.Author)
+		}
+		if entry.Commit != "" {
+			line += fmt.Sprintf(" at %.7s", entry.Commit)
+		}
+		if _, err := fmt.Fprintf(w, "%s  %s\n", entry.CreatedAt.Format(time.RFC3339), line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// metadataChanges compares metadata before and after it was edited, returning
+// the entries that were added or given a new value, and the names of those
+// that were deleted.
+func metadataChanges(before []*metadata.Entry, after []metadata.Entry) ([]*metadata.Entry, []string) {
+	values := map[string]string{}
+	typed := map[string]bool{}
+	for _, entry := range before {
+		values[entry.Name] = entry.Value
+		typed[entry.Name] = entry.Typed()
+	}
+
+	changed := []*metadata.Entry{}
+	kept := map[string]bool{}
+	for _, entry := range after {
+		kept[entry.Name] = true
+		if value, ok := values[entry.Name]; !ok || value != entry.Value {
+			changed = append(changed, editedEntry(typed[entry.Name], entry))
+		}
+	}
+
+	removed := []string{}
+	for _, entry := range before {
+		if !kept[entry.Name] {
+			removed = append(removed, entry.Name)
+		}
+	}
+
+	return changed, removed
+}
+
+// editedEntry keeps an edited value typed if it was typed before, or was
+// edited as JSON, and is still valid JSON.
+func editedEntry(typed bool, edited metadata.Entry) *metadata.Entry {
+	if typed || edited.Typed() {
+		if entry, err := metadata.NewTypedKeyValue(edited.Name, edited.Value); err == nil {
+			return entry
+		}
+	}
+	return metadata.NewKeyValue(edited.Name, edited.Value)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/endiangroup/specstack"
+)
+
+// printProgress prints a table of the scenarios in each state per story, and
+// another per actor if there are any.
+func printProgress(w io.Writer, report *specstack.ProgressReport) error {
+	if err := printProgressTable(w, "STORY", report.States, report.Stories); err != nil {
+		return err
+	}
+	if len(report.Actors) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	return printProgressTable(w, "ACTOR", report.States, report.Actors)
+}
+
+func printProgressTable(w io.Writer, heading string, states []string, counts []specstack.ProgressCount) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "%s\t%s\n", heading, strings.Join(states, "\t"))
+
+	for _, count := range counts {
+		row := make([]string, len(states))
+		for i, state := range states {
+			row[i] = strconv.Itoa(count.Counts[state])
+		}
+		fmt.Fprintf(table, "%s\t%s\n", count.Name, strings.Join(row, "\t"))
+	}
+	return table.Flush()
+}
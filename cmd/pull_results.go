@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/endiangroup/specstack/repository"
+)
+
+// printMetadataMerge reports how the metadata pulled from a remote was
+// combined with the local metadata.
+func printMetadataMerge(w io.Writer, merge *repository.MetadataMerge) error {
+	if merge.UpToDate {
+		_, err := fmt.Fprintf(w, "Metadata is already up to date with %s\n", merge.Remote)
+		return err
+	}
+
+	how := "Merged"
+	if merge.FastForward {
+		how = "Fast-forwarded"
+	}
+
+	if _, err := fmt.Fprintf(
+		w,
+		"%s metadata from %s: %s added to %s\n",
+		how,
+		merge.Remote,
+		plural(merge.Entries, "entry", "entries"),
+		plural(merge.Objects, "object", "objects"),
+	); err != nil {
+		return err
+	}
+
+	if merge.Conflicts == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(
+		w,
+		"%s changed on both sides and combined, %s kept once\n",
+		plural(merge.Conflicts, "object", "objects"),
+		plural(merge.Duplicates, "duplicate entry", "duplicate entries"),
+	)
+	return err
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/endiangroup/specstack/specification"
+)
+
+type queryResult struct {
+	Story    string `json:"story"`
+	Scenario string `json:"scenario,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+}
+
+func newStoryQueryResult(story *specification.Story) queryResult {
+	return queryResult{
+		Story: story.Name,
+		File:  story.SourceIdentifier,
+	}
+}
+
+func newScenarioQueryResult(scenario *specification.Scenario) queryResult {
+	return queryResult{
+		Story:    scenario.Story.Name,
+		Scenario: scenario.Name,
+		File:     scenario.Story.SourceIdentifier,
+		Line:     scenario.LineNumber(),
+	}
+}
+
+func (r queryResult) location() string {
+	if r.Line == 0 {
+		return r.File
+	}
+	return fmt.Sprintf("%s:%d", r.File, r.Line)
+}
+
+func printQueryResultsJSON(w io.Writer, results []queryResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func printQueryResultsTable(w io.Writer, results []queryResult) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		if r.Scenario == "" {
+			fmt.Fprintf(table, "%s\t%s\n", r.Story, r.location())
+		} else {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", r.Story, r.Scenario, r.location())
+		}
+	}
+	return table.Flush()
+}
@@ -29,13 +29,26 @@ func main() {
 		os.Stderr,
 	)
 	app := specstack.Application{
-		ConfigAsserter:      developer,
-		ConfigGetListSetter: developer,
-		MetadataGetAdder:    developer,
-		MetadataTransferer:  developer,
-		PushPuller:          developer,
-		RepoHooker:          developer,
-		Repository:          gitRepo,
+		ConfigAsserter:         developer,
+		ConfigGetListSetter:    developer,
+		MetadataGetter:         developer,
+		MetadataEditor:         developer,
+		SpecificationQuerier:   developer,
+		SpecificationDiffer:    developer,
+		ActorLister:            developer,
+		ScenarioIDAssigner:     developer,
+		MetadataChecker:        developer,
+		MetadataFinder:         developer,
+		MetadataExportImporter: developer,
+		Discusser:              developer,
+		ProgressTracker:        developer,
+		RevisionSelector:       developer,
+		MetadataTransferer:     developer,
+		PushPuller:             developer,
+		PushQueuer:             developer,
+		RepoHooker:             developer,
+		HookManager:            developer,
+		Repository:             gitRepo,
 	}
 	cobra := cmd.WireUpCobraHarness(
 		cmd.NewCobraHarness(&app, os.Stdin, os.Stdout, os.Stderr),
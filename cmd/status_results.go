@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/endiangroup/specstack/metadata"
+)
+
+const pendingPushTimeFormat = "2006-01-02 15:04"
+
+// printPendingPushes lists the metadata changes in the outbox, with the reason
+// the last of them could not be pushed.
+func printPendingPushes(w io.Writer, remote string, pending []metadata.PendingPush) error {
+	if len(pending) == 0 {
+		_, err := fmt.Fprintln(w, "No metadata changes waiting to be pushed")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(
+		w,
+		"%s waiting to be pushed to %s:\n",
+		plural(len(pending), "metadata change", "metadata changes"),
+		remote,
+	); err != nil {
+		return err
+	}
+
+	for _, push := range pending {
+		if _, err := fmt.Fprintf(
+			w,
+			"  %s  %s\n",
+			push.QueuedAt.Format(pendingPushTimeFormat),
+			strings.Join(push.Names, ", "),
+		); err != nil {
+			return err
+		}
+	}
+
+	if reason := pending[len(pending)-1].Reason; reason != "" {
+		_, err := fmt.Fprintf(w, "The last push failed: %s\n", reason)
+		return err
+	}
+	return nil
+}
@@ -44,6 +44,14 @@ func projectGet(p *Project, key string) (string, error) {
 		return p.PushingMode, nil
 	case KeyProjectPullingMode:
 		return p.PullingMode, nil
+	case KeyProjectDialect:
+		return p.Dialect, nil
+	case KeyProjectInclude:
+		return p.Include, nil
+	case KeyProjectExclude:
+		return p.Exclude, nil
+	case KeyProjectWorkflow:
+		return p.Workflow, nil
 	}
 
 	return "", ErrKeyNotFound(key)
@@ -13,6 +13,10 @@ const (
 	KeyProjectFeaturesDir        = "featuresdir"
 	KeyProjectPushingMode        = "pushingmode"
 	KeyProjectPullingMode        = "pullingmode"
+	KeyProjectDialect            = "dialect"
+	KeyProjectInclude            = "include"
+	KeyProjectExclude            = "exclude"
+	KeyProjectWorkflow           = "workflow"
 )
 
 func fetchPrefix(key string) prefix {
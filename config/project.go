@@ -1,5 +1,11 @@
 package config
 
+import (
+	"strings"
+
+	"github.com/endiangroup/specstack/progress"
+)
+
 const (
 	ModeAuto     = "auto"
 	ModeSemiAuto = "semi-auto"
@@ -15,6 +21,8 @@ func newProjectWithDefaults() *Project {
 		FeaturesDir: "./features",
 		PushingMode: ModeAuto,
 		PullingMode: ModeSemiAuto,
+		Dialect:     "en",
+		Workflow:    progress.DefaultWorkflow,
 	}
 }
 
@@ -24,4 +32,60 @@ type Project struct {
 	FeaturesDir string
 	PushingMode string
 	PullingMode string
+	Dialect     string
+	Include     string
+	Exclude     string
+	Workflow    string
+}
+
+// A FeatureRoot is a directory of feature files, optionally labelled so its
+// stories can be told apart from those of other roots.
+type FeatureRoot struct {
+	Label string
+	Path  string
+}
+
+// FeatureRoots returns the comma separated feature directories, each of which
+// may be prefixed with a label, e.g. "billing:services/billing/features".
+func (p *Project) FeatureRoots() []FeatureRoot {
+	roots := []FeatureRoot{}
+	for _, entry := range splitPatterns(p.FeaturesDir) {
+		root := FeatureRoot{Path: entry}
+		if parts := strings.SplitN(entry, ":", 2); len(parts) == 2 && !strings.ContainsAny(parts[0], "/.") {
+			root = FeatureRoot{Label: parts[0], Path: parts[1]}
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// IncludePatterns returns the comma separated patterns of files the
+// specification is limited to.
+func (p *Project) IncludePatterns() []string {
+	return splitPatterns(p.Include)
+}
+
+// ExcludePatterns returns the comma separated patterns of files left out of
+// the specification.
+func (p *Project) ExcludePatterns() []string {
+	return splitPatterns(p.Exclude)
+}
+
+// WorkflowDefinition returns the definition of the workflow scenarios move
+// through, or the default one if the project has none.
+func (p *Project) WorkflowDefinition() string {
+	if p.Workflow == "" {
+		return progress.DefaultWorkflow
+	}
+	return p.Workflow
+}
+
+func splitPatterns(value string) []string {
+	patterns := []string{}
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
 }
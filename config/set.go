@@ -36,6 +36,14 @@ func projectSet(p *Project, key, value string) error {
 		p.PushingMode = value
 	case KeyProjectPullingMode:
 		p.PullingMode = value
+	case KeyProjectDialect:
+		p.Dialect = value
+	case KeyProjectInclude:
+		p.Include = value
+	case KeyProjectExclude:
+		p.Exclude = value
+	case KeyProjectWorkflow:
+		p.Workflow = value
 	default:
 		return ErrKeyNotFound(key)
 	}
@@ -19,6 +19,10 @@ func projectToMap(p *Project, key prefix, configMap map[string]string) map[strin
 	configMap[key.Append(KeyProjectFeaturesDir)] = p.FeaturesDir
 	configMap[key.Append(KeyProjectPushingMode)] = p.PushingMode
 	configMap[key.Append(KeyProjectPullingMode)] = p.PullingMode
+	configMap[key.Append(KeyProjectDialect)] = p.Dialect
+	configMap[key.Append(KeyProjectInclude)] = p.Include
+	configMap[key.Append(KeyProjectExclude)] = p.Exclude
+	configMap[key.Append(KeyProjectWorkflow)] = p.Workflow
 
 	return configMap
 }
@@ -1,5 +1,7 @@
 package config
 
+import "fmt"
+
 func newUser() *User {
 	return &User{}
 }
@@ -15,3 +17,9 @@ type User struct {
 	Name  string
 	Email string
 }
+
+// String formats the user the way git formats authors, e.g.
+// "Jane Doe <jane@example.com>".
+func (u *User) String() string {
+	return fmt.Sprintf("%s <%s>", u.Name, u.Email)
+}
@@ -0,0 +1,167 @@
+// Package discussion keeps threads of conversation about stories and
+// scenarios. Each post is stored as a metadata entry named
+// metadata.DiscussionName, so it's synced with the rest of the metadata and
+// records its author and time in the same way.
+package discussion
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/endiangroup/specstack/metadata"
+)
+
+// An Action is what a post does to its thread.
+type Action string
+
+const (
+	ActionStart   Action = "start"
+	ActionReply   Action = "reply"
+	ActionResolve Action = "resolve"
+	ActionReopen  Action = "reopen"
+)
+
+// idLength is the number of random bytes in the ID of a post, which is
+// printed in hexadecimal.
+const idLength = 4
+
+// A Post is a message in a thread, or a change to whether it's resolved.
+// Entry is the entry it's stored in, which records its author and time.
+type Post struct {
+	ID      string          `json:"id"`
+	Thread  string          `json:"thread"`
+	Action  Action          `json:"action"`
+	Message string          `json:"message,omitempty"`
+	Entry   *metadata.Entry `json:"-"`
+}
+
+// A Thread is a conversation, started by its first post. It is resolved if
+// its latest resolve or reopen post resolved it.
+type Thread struct {
+	ID       string
+	Posts    []*Post
+	Resolved bool
+}
+
+// Start returns the ID of a new thread, and the entry of the post starting it.
+func Start(message string) (string, *metadata.Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	entry, err := newEntry(&Post{ID: id, Thread: id, Action: ActionStart, Message: message})
+	return id, entry, err
+}
+
+// Reply returns the entry of a post replying to a thread.
+func (t *Thread) Reply(message string) (*metadata.Entry, error) {
+	return t.post(ActionReply, message)
+}
+
+// Resolve returns the entry of a post resolving a thread.
+func (t *Thread) Resolve() (*metadata.Entry, error) {
+	if t.Resolved {
+		return nil, fmt.Errorf("thread %s is already resolved", t.ID)
+	}
+	return t.post(ActionResolve, "")
+}
+
+// Reopen returns the entry of a post reopening a resolved thread.
+func (t *Thread) Reopen() (*metadata.Entry, error) {
+	if !t.Resolved {
+		return nil, fmt.Errorf("thread %s is not resolved", t.ID)
+	}
+	return t.post(ActionReopen, "")
+}
+
+func (t *Thread) post(action Action, message string) (*metadata.Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return newEntry(&Post{ID: id, Thread: t.ID, Action: action, Message: message})
+}
+
+// Threads gathers discussion entries into threads, in the order they were
+// started. A post recorded more than once, for example when the metadata of an
+// object was transferred twice, counts once.
+func Threads(entries []*metadata.Entry) ([]*Thread, error) {
+	threads := []*Thread{}
+	byID := map[string]*Thread{}
+	seen := map[string]bool{}
+
+	for _, entry := range entries {
+		post := &Post{}
+		if err := entry.Decode(post); err != nil {
+			return nil, err
+		}
+		if seen[post.ID] {
+			continue
+		}
+		seen[post.ID] = true
+		post.Entry = entry
+
+		thread, ok := byID[post.Thread]
+		if !ok {
+			thread = &Thread{ID: post.Thread}
+			byID[post.Thread] = thread
+			threads = append(threads, thread)
+		}
+		thread.add(post)
+	}
+
+	return threads, nil
+}
+
+func (t *Thread) add(post *Post) {
+	t.Posts = append(t.Posts, post)
+	switch post.Action {
+	case ActionResolve:
+		t.Resolved = true
+	case ActionReopen:
+		t.Resolved = false
+	}
+}
+
+// Find returns the thread whose ID starts with the given prefix, which must
+// only match one.
+func Find(threads []*Thread, prefix string) (*Thread, error) {
+	var found *Thread
+	for _, thread := range threads {
+		if !strings.HasPrefix(thread.ID, prefix) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("more than one thread starts with %s", prefix)
+		}
+		found = thread
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no thread %s", prefix)
+	}
+	return found, nil
+}
+
+func newEntry(post *Post) (*metadata.Entry, error) {
+	data, err := json.Marshal(post)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := metadata.NewKeyValue(metadata.DiscussionName, post.Message)
+	entry.Data = json.RawMessage(data)
+	return entry, nil
+}
+
+func newID() (string, error) {
+	id := make([]byte, idLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
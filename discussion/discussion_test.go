@@ -0,0 +1,75 @@
+package discussion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/endiangroup/specstack/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PostsAreGatheredIntoThreads(t *testing.T) {
+	id, start, err := Start("Should this cover Amex?")
+	require.Nil(t, err)
+	start.Author = "Jane Doe <jane@example.com>"
+	start.CreatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, other, err := Start("Is the card saved?")
+	require.Nil(t, err)
+
+	threads, err := Threads([]*metadata.Entry{start, other})
+	require.Nil(t, err)
+	require.Len(t, threads, 2)
+	require.Equal(t, id, threads[0].ID)
+
+	reply, err := threads[0].Reply("Yes")
+	require.Nil(t, err)
+	resolve, err := threads[0].Resolve()
+	require.Nil(t, err)
+
+	threads, err = Threads([]*metadata.Entry{start, other, reply, resolve, reply})
+	require.Nil(t, err)
+	require.Len(t, threads, 2)
+
+	thread := threads[0]
+	require.True(t, thread.Resolved)
+	require.Len(t, thread.Posts, 3)
+	require.Equal(t, ActionStart, thread.Posts[0].Action)
+	require.Equal(t, "Should this cover Amex?", thread.Posts[0].Message)
+	require.Equal(t, start, thread.Posts[0].Entry)
+	require.Equal(t, ActionReply, thread.Posts[1].Action)
+	require.Equal(t, ActionResolve, thread.Posts[2].Action)
+	require.False(t, threads[1].Resolved)
+}
+
+func Test_ThreadsCanBeReopened(t *testing.T) {
+	thread := &Thread{ID: "abc"}
+
+	_, err := thread.Reopen()
+	require.EqualError(t, err, "thread abc is not resolved")
+
+	resolve, err := thread.Resolve()
+	require.Nil(t, err)
+	thread.add(&Post{Action: ActionResolve})
+	require.Equal(t, metadata.DiscussionName, resolve.Name)
+
+	_, err = thread.Resolve()
+	require.EqualError(t, err, "thread abc is already resolved")
+
+	_, err = thread.Reopen()
+	require.Nil(t, err)
+}
+
+func Test_ThreadsAreFoundByAPrefixOfTheirID(t *testing.T) {
+	threads := []*Thread{{ID: "ab12"}, {ID: "ab34"}, {ID: "cd56"}}
+
+	found, err := Find(threads, "cd")
+	require.Nil(t, err)
+	require.Equal(t, threads[2], found)
+
+	_, err = Find(threads, "ab")
+	require.EqualError(t, err, "more than one thread starts with ab")
+
+	_, err = Find(threads, "ef")
+	require.EqualError(t, err, "no thread ef")
+}
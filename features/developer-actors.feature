@@ -0,0 +1,56 @@
+Feature: Describe actors and their goals
+  As a Developer
+  I want to describe the actors my specification is written for
+  So I can see which stories serve each of their goals
+
+  Scenario: List actors, their goals and the stories that serve them
+    Given I have a configured project directory
+    And I have a file called "features/developer.actor" with the following content:
+      """
+      Actor: Developer
+
+        Goal: Manage custom metadata
+      """
+    And I have a file called "features/metadata.feature" with the following content:
+      """
+      Feature: Metadata
+        As a Developer
+        I want to manage custom metadata
+      """
+    When I run "actors"
+    Then I should see the following:
+      """
+      Developer (features/developer.actor)
+        Goal: Manage custom metadata
+          - Metadata (features/metadata.feature)
+      """
+
+  Scenario: Story narrative names an unknown actor
+    Given I have a configured project directory
+    And I have a file called "features/developer.actor" with the following content:
+      """
+      Actor: Developer
+      """
+    And I have a file called "features/testing.feature" with the following content:
+      """
+      Feature: Testing
+        As a Tester
+        I want to test things
+      """
+    When I run "actors"
+    Then I should see a warning message informing me "features/testing.feature: no actor found for role Tester"
+
+  Scenario: Add metadata to an actor
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/developer.actor" with the following content:
+      """
+      Actor: Developer
+      """
+    When I run "metadata add --actor Developer key=value"
+    Then I should see no errors
+    When I run "metadata list --actor Developer"
+    Then I should see the following:
+      """
+      key: value
+      """
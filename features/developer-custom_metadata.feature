@@ -163,6 +163,79 @@ Feature: Manage custom metadata
     Then The metadata "key" should be added to scenario "StoryB+1" with the value "value"
     And I should see no errors
 
+  Scenario: Successfully add metadata to a scenario outline example
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario Outline: Eating
+          Given there are <start> cucumbers
+          Then I should have <left> cucumbers
+
+          Examples:
+            | start | left |
+            | 12    | 7    |
+            | 20    | 15   |
+      """
+    When I run "metadata add --scenario Eating#2 --story StoryA key=value"
+    Then The metadata "key" should be added to scenario "StoryA+1#2" with the value "value"
+    And I should see no errors
+
+  Scenario: Successfully add metadata to a scenario filtered by tags
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        @slow
+        Scenario: AlphaOne
+          When I do something
+        @wip
+        Scenario: AlphaTwo
+          When I do something else
+      """
+    When I run "metadata add --scenario Alpha --tags @wip key=value"
+    Then The metadata "key" should be added to scenario "AlphaTwo" with the value "value"
+    And I should see no errors
+
+  Scenario: Show metadata attached to a scenario at a past revision
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: AlphaOne
+          When I do something
+      """
+    And I make a commit
+    And I tag the commit as "v1"
+    And I run "metadata add --scenario AlphaOne key=value"
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: BetaOne
+          Given a completely different context
+          Then the outcome is unrelated
+      """
+    When I run "metadata list --rev v1 --scenario AlphaOne"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Unknown revision
+    Given I have a configured project directory
+    When I run "metadata list --rev v9 --story story1"
+    Then I should see an error message informing me "unknown revision v9"
+
+  Scenario: Adding metadata to a past revision
+    Given I have a configured project directory
+    And I make a commit
+    And I tag the commit as "v1"
+    When I run "metadata add --rev v1 --story story1 key=value"
+    Then I should see an error message informing me "metadata can only be added to the working tree, not to a past revision"
+
   Scenario: Show metadata attached to a scenario
     Given I have a configured project directory
     And I have a story called "storyA"
@@ -0,0 +1,69 @@
+Feature: Describe specification changes
+  As a Developer
+  I want to see how the behaviour described by my specification changed between revisions
+  So I can describe behaviour changes in a code review
+
+  Scenario: Show modified and added scenarios since a revision
+    Given I have a configured project directory
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: AlphaOne
+          Given I have a project
+          When I do something
+      """
+    And I make a commit
+    And I tag the commit as "v1"
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: AlphaOne
+          Given I have a project
+          When I do something else
+        Scenario: AlphaTwo
+          Then an entirely new outcome happens
+      """
+    When I run "diff v1"
+    Then I should see the following:
+      """
+      modified: AlphaOne (features/storyA.feature:2)
+            I have a project
+          - I do something
+          + I do something else
+      added: AlphaTwo (features/storyA.feature:5)
+      """
+
+  Scenario: Show changes between two revisions as Markdown
+    Given I have a configured project directory
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: AlphaOne
+          When I do something
+      """
+    And I make a commit
+    And I tag the commit as "v1"
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: AlphaRenamed
+          When I do something
+      """
+    And I make a commit
+    And I tag the commit as "v2"
+    When I run "diff v1 v2 --format markdown"
+    Then I should see the following:
+      """
+      ### Renamed
+      - **AlphaOne -> AlphaRenamed** `features/storyA.feature:2`
+      """
+
+  Scenario: No changes since a revision
+    Given I have a configured project directory
+    And I make a commit
+    And I tag the commit as "v1"
+    When I run "diff v1"
+    Then I should see the following:
+      """
+      No scenarios changed
+      """
@@ -0,0 +1,91 @@
+Feature: Discussions
+  As a Developer
+  I want to discuss stories and scenarios in threads
+  So that implementation conversations are kept with the specification
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+
+        Scenario: Refund
+          Given I have paid
+      """
+
+  Scenario: Start a discussion on a scenario
+    When I run "discuss --scenario Pay Amex?"
+    Then I should see no errors
+    And I should see the following:
+      """
+      Started thread
+      """
+    When I run "discuss list --scenario Pay"
+    Then I should see the following:
+      """
+      scenario Pay
+      (open)
+      Speck Stack at
+      : Amex?
+      """
+
+  Scenario: Reply to and resolve a thread
+    Given I run "discuss --scenario Pay Amex?"
+    When I run "discuss reply --scenario Pay <thread> Later" on the thread I started
+    And I run "discuss resolve --scenario Pay <thread>" on the thread I started
+    Then I should see no errors
+    When I run "discuss list"
+    Then I should see the following:
+      """
+      No open discussions
+      """
+    When I run "discuss list --all"
+    Then I should see the following:
+      """
+      scenario Pay
+      (resolved)
+      : Amex?
+      : Later
+      resolved the thread
+      """
+
+  Scenario: Reopen a resolved thread
+    Given I run "discuss --scenario Pay Amex?"
+    And I run "discuss resolve --scenario Pay <thread>" on the thread I started
+    When I run "discuss reopen --scenario Pay <thread>" on the thread I started
+    Then I should see no errors
+    When I run "discuss list --scenario Pay"
+    Then I should see the following:
+      """
+      (open)
+      reopened the thread
+      """
+
+  Scenario: Discussions are not metadata values
+    Given I run "discuss --scenario Pay Amex?"
+    And I run "metadata add --scenario Pay owner=alice"
+    When I run "metadata list --scenario Pay"
+    Then I should not see "discussion"
+    And I should see the following:
+      """
+      owner: alice
+      """
+
+  Scenario: Unknown threads are reported
+    When I run "discuss reply --scenario Pay abc Later"
+    Then I should see an error message informing me "no thread abc"
+
+  Scenario: The thread ID is shown when the discussion can't be pushed
+    Given I have set the pushing mode to automatic
+    But I have not set a git remote
+    When I run "discuss --scenario Pay Amex?"
+    Then I should see an error message informing me "the change was queued to be pushed later"
+    When I run "discuss list --scenario Pay"
+    Then I should see the following:
+      """
+      Started thread
+      """
@@ -0,0 +1,61 @@
+Feature: Read features from several roots
+  As a Developer
+  I want to keep features under several directories of my project
+  So I can specify each service of a monorepo next to its code
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "services/billing/features/checkout.feature" with the following content:
+      """
+      Feature: Billing checkout
+
+        Scenario: Pay by card
+      """
+    And I have a file called "services/auth/features/checkout.feature" with the following content:
+      """
+      Feature: Auth checkout
+
+        Scenario: Login
+      """
+    And I run "config set project.featuresdir=billing:services/billing/features,auth:services/auth/features"
+
+  Scenario: Stories from every root are listed
+    When I run "query --type story"
+    Then I should see the following:
+      """
+      Billing checkout
+      Auth checkout
+      """
+
+  Scenario: Address a story by the label of its root
+    When I run "metadata add --story billing:checkout key=value"
+    Then I should see no errors
+    When I run "metadata list --story auth:checkout"
+    Then I should not see "key: value"
+    When I run "metadata list --story billing:checkout"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Address a scenario in a story by the label of its root
+    When I run "metadata add --story auth:checkout --scenario Login key=value"
+    Then I should see no errors
+    When I run "metadata list --story auth:checkout --scenario Login"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Stories with the same narrative in different roots are reported
+    Given I have a file called "services/auth/features/pay.feature" with the following content:
+      """
+      Feature: Pay
+      """
+    And I have a file called "services/billing/features/pay.feature" with the following content:
+      """
+      Feature: Pay
+      """
+    When I run "ids check"
+    Then I should see an error message informing me "duplicate story narrative Pay: services/auth/features/pay.feature, services/billing/features/pay.feature"
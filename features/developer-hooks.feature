@@ -0,0 +1,70 @@
+Feature: Manage git hooks
+  As a Developer
+  I want spec's git hooks to live alongside the hooks my team already uses
+  So that metadata stay in sync without breaking our other tools
+
+  Scenario: Missing hooks are installed
+    Given I have a git-initialised project directory
+    When I run "hooks status"
+    Then I should see the following:
+      """
+      HOOK         STATE
+      pre-push     installed
+      post-merge   installed
+      post-commit  installed
+      """
+
+  Scenario: Existing hooks are reported rather than skipped silently
+    Given I have a git-initialised project directory
+    And I have a file called ".githooks/pre-push" with the following content:
+      """
+      #!/bin/sh
+      echo custom
+      """
+    And git runs hooks from ".githooks"
+    When I run "status"
+    Then I should see the following:
+      """
+      1 of 3 git hooks doesn't run spec, see spec hooks status
+      """
+    When I run "hooks status"
+    Then I should see the following:
+      """
+      pre-push     not installed, another hook is there
+      """
+
+  Scenario: Installing chains into existing hooks
+    Given I have a git-initialised project directory
+    And I have a file called ".githooks/pre-push" with the following content:
+      """
+      #!/bin/sh
+      echo custom
+      """
+    And git runs hooks from ".githooks"
+    When I run "hooks install"
+    Then I should see the following:
+      """
+      HOOK         STATE
+      pre-push     added to the existing hook
+      post-merge   installed
+      post-commit  installed
+      """
+
+  Scenario: Uninstalling restores existing hooks and removes spec's own
+    Given I have a git-initialised project directory
+    And I have a file called ".githooks/pre-push" with the following content:
+      """
+      #!/bin/sh
+      echo custom
+      """
+    And git runs hooks from ".githooks"
+    When I run "hooks install"
+    And I run "hooks uninstall"
+    And I run "hooks status"
+    Then I should see the following:
+      """
+      HOOK         STATE
+      pre-push     not installed, another hook is there
+      post-merge   not installed
+      post-commit  not installed
+      """
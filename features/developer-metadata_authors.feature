@@ -0,0 +1,46 @@
+Feature: Metadata authors
+  As a Developer
+  I want to know who wrote each piece of metadata
+  So I can ask them about it or see only my own
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+      """
+
+  Scenario: List shows who wrote the metadata
+    When I run "metadata add --scenario Pay status=draft"
+    And I run "metadata list --scenario Pay"
+    Then I should see the following:
+      """
+      status: draft  (by Speck Stack)
+      """
+
+  Scenario: Filter metadata by its author
+    Given I run "metadata add --scenario Pay status=draft"
+    And I run "config set user.name=Jane"
+    And I run "config set user.email=jane@example.com"
+    And I run "metadata add --scenario Pay owner=jane"
+    When I run "metadata list --scenario Pay --author speck"
+    Then I should not see "owner"
+    And I should see the following:
+      """
+      status: draft  (by Speck Stack)
+      """
+
+  Scenario: The history shows the author of every entry
+    Given I run "metadata add --scenario Pay status=draft"
+    And I run "config set user.name=Jane"
+    And I run "metadata set --scenario Pay status=done"
+    When I run "metadata list --scenario Pay --history --author jane"
+    Then I should not see "draft"
+    And I should see the following:
+      """
+      status: done  by Jane <dev@specstack.io>
+      """
@@ -0,0 +1,136 @@
+Feature: Export and import metadata
+  As a Developer
+  I want to export all metadata to a file and import it back
+  So that I can edit it in a spreadsheet, migrate it and back it up
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        @id:pay
+        Scenario: Pay
+          Given I have a card
+
+        Scenario: Refund
+          Given I have paid
+      """
+    And I run "metadata add --story Checkout status=open"
+    And I run "metadata add --scenario Pay owner=alice estimate:=5"
+
+  Scenario: Export metadata as CSV
+    When I run "metadata export --format csv"
+    Then I should see no errors
+    And I should see the following:
+      """
+      kind,id,story,name,file,line,hash,key,value,json
+      """
+    And I should see the following:
+      """
+      scenario,pay,Checkout,Pay,features/checkout.feature,4,
+      """
+    And I should see the following:
+      """
+      ,estimate,5,true
+      """
+
+  Scenario: Export metadata as YAML
+    When I run "metadata export --format yaml"
+    Then I should see no errors
+    And I should see the following:
+      """
+      - kind: "scenario"
+        id: "pay"
+        story: "Checkout"
+        name: "Pay"
+        file: "features/checkout.feature"
+        line: 4
+      """
+
+  Scenario: A dry run reports what would be imported
+    Given I have a file called "metadata.csv" with the following content:
+      """
+      kind,id,story,name,file,line,hash,key,value,json
+      scenario,pay,Checkout,Paying,features/checkout.feature,4,,owner,bob,false
+      scenario,,Checkout,Refnd,features/checkout.feature,7,,estimate,2,true
+      """
+    When I run "metadata import metadata.csv --dry-run"
+    Then I should see no errors
+    And I should see the following:
+      """
+      scenario Checkout/Paying: would set owner (matched by id)
+      scenario Checkout/Refnd: would set estimate (matched Checkout/Refund by fuzzy match)
+      Dry run, no metadata was imported
+      """
+    When I run "metadata list --scenario Pay --format plain"
+    Then I should see the following:
+      """
+      owner   : alice
+      """
+
+  Scenario: Import metadata from JSON
+    Given I have a file called "metadata.json" with the following content:
+      """
+      [{"Kind": "scenario", "Story": "Checkout", "Name": "Refund", "Metadata": [{"Name": "owner", "Value": "bob"}]}]
+      """
+    When I run "metadata import metadata.json"
+    Then I should see no errors
+    And I should see the following:
+      """
+      scenario Checkout/Refund: set owner (matched by name)
+      """
+    When I run "metadata list --scenario Refund --format plain"
+    Then I should see the following:
+      """
+      owner: bob
+      """
+
+  Scenario: Import metadata from YAML written by hand
+    Given I have a file called "metadata.yaml" with the following content:
+      """
+      - kind: scenario
+        story: 'Checkout'
+        name: Refund
+        metadata:
+          - name: owner
+            value: 'bob'
+          - name: notes
+            value: >
+              ask the
+              bank first
+      """
+    When I run "metadata import metadata.yaml"
+    Then I should see no errors
+    When I run "metadata list --scenario Refund --format full"
+    Then I should see the following:
+      """
+      notes: ask the bank first
+      owner: bob
+      """
+
+  Scenario: Nothing is imported when a record matches nothing
+    Given I have a file called "metadata.yaml" with the following content:
+      """
+      - kind: story
+        name: Checkout
+        metadata:
+          - name: status
+            value: closed
+      - kind: scenario
+        story: Returns
+        name: Exchange
+        metadata: []
+      """
+    When I run "metadata import metadata.yaml"
+    Then I should see the following problems:
+      """
+      scenario Returns/Exchange: no scenario matches
+      """
+    And I should see an error message informing me "1 records could not be imported, so nothing was recorded"
+    When I run "metadata export --format csv"
+    Then I should see the following:
+      """
+      ,status,open,false
+      """
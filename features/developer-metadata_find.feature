@@ -0,0 +1,59 @@
+Feature: Find metadata
+  As a Developer
+  I want to search the whole specification by metadata
+  So I can see everything that is owned by someone or still to be done
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+
+        Scenario: Refund
+          Given I have paid
+      """
+    And I run "metadata add --story Checkout status=open"
+    And I run "metadata add --scenario Pay owner=alice estimate:=5"
+    And I run "metadata add --scenario Refund owner=bob estimate:=2"
+
+  Scenario: Find scenarios by an exact value
+    When I run "metadata find owner=alice"
+    Then I should not see "Refund"
+    And I should see the following:
+      """
+      Pay
+      features/checkout.feature:3
+      """
+
+  Scenario: Find scenarios by a numeric comparison
+    When I run "metadata find estimate>=3"
+    Then I should not see "Refund"
+    And I should see the following:
+      """
+      features/checkout.feature:3
+      """
+
+  Scenario: Find scenarios by a pattern
+    When I run "metadata find owner~^b"
+    Then I should not see "Pay"
+    And I should see the following:
+      """
+      Refund
+      features/checkout.feature:6
+      """
+
+  Scenario: Find stories whose metadata is set
+    When I run "metadata find --type story status --format json"
+    Then I should see the following:
+      """
+      "story": "Checkout",
+      "file": "features/checkout.feature"
+      """
+
+  Scenario: Predicates must be valid
+    When I run "metadata find estimate>many"
+    Then I should see an error message informing me "estimate must be compared with a number, got 'many'"
@@ -0,0 +1,89 @@
+Feature: Metadata formats
+  As a Developer
+  I want to print metadata as JSON, YAML or a table, and read it back in
+  So that I can use it in other tools and copy it between objects
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+
+        Scenario: Refund
+          Given I have paid
+      """
+    And I run "metadata add --scenario Pay owner=alice estimate:=5 docs=https://example.com/pay"
+
+  Scenario: List metadata as JSON
+    When I run "metadata list --scenario Pay --format json"
+    Then I should see no errors
+    And I should see the following:
+      """
+          "Name": "estimate",
+          "Value": "5",
+          "Data": 5,
+      """
+
+  Scenario: List metadata as YAML
+    When I run "metadata list --scenario Pay --format yaml"
+    Then I should see no errors
+    And I should see the following:
+      """
+      - name: "docs"
+        value: "https://example.com/pay"
+      """
+
+  Scenario: List metadata as a table
+    When I run "metadata list --scenario Pay --format table"
+    Then I should see no errors
+    And I should see the following:
+      """
+      NAME      VALUE                    AUTHOR
+      docs      https://example.com/pay  Speck Stack
+      estimate  5                        Speck Stack
+      owner     alice                    Speck Stack
+      """
+
+  Scenario: Unknown formats are refused
+    When I run "metadata list --scenario Pay --format xml"
+    Then I should see an error message informing me "unknown format"
+
+  Scenario: Metadata can be piped back in
+    When I run "metadata set --scenario Refund --stdin --format yaml" with the following input:
+      """
+      - name: "owner"
+        value: "bob"
+      - name: "estimate"
+        data: 3
+      """
+    Then I should see no errors
+    When I run "metadata list --scenario Refund --format full"
+    Then I should see the following:
+      """
+      estimate: 3
+      owner   : bob
+      """
+
+  Scenario: Metadata is piped in as JSON by default
+    When I run "metadata set --scenario Refund --stdin" with the following input:
+      """
+      [{"Name": "owner", "Value": "bob"}, {"Name": "estimate", "Data": 3}]
+      """
+    Then I should see no errors
+    When I run "metadata list --scenario Refund --format full"
+    Then I should see the following:
+      """
+      estimate: 3
+      owner   : bob
+      """
+
+  Scenario: Truncated values are not piped back in
+    When I run "metadata set --scenario Refund --stdin --format plain" with the following input:
+      """
+      notes: Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ...
+      """
+    Then I should see an error message informing me "the value of notes was truncated when printed"
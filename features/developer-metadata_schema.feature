@@ -0,0 +1,67 @@
+Feature: Metadata schema
+  As a Developer
+  I want the project to define the metadata it allows
+  So that we don't end up with the same thing recorded in different ways
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+      """
+    And I have a file called ".specschema.json" with the following content:
+      """
+      {
+        "keys": {
+          "status": {
+            "type": "string",
+            "enum": ["todo", "doing", "done"],
+            "appliesTo": ["story", "scenario"],
+            "required": true
+          },
+          "estimate": {"type": "number", "appliesTo": ["scenario"]}
+        }
+      }
+      """
+
+  Scenario: Add metadata that matches the schema
+    When I run "metadata add --scenario Pay status=done estimate:=3"
+    Then I should see no errors
+
+  Scenario: Keys that aren't in the schema are rejected
+    When I run "metadata add --scenario Pay Status=done"
+    Then I should see an error message informing me "Field 'Status' is not in the metadata schema"
+
+  Scenario: Values must be one of the allowed ones
+    When I run "metadata add --scenario Pay status=finished"
+    Then I should see an error message informing me "Field 'status' must be one of todo, doing, done"
+
+  Scenario: Values must be of the right type
+    When I run "metadata add --scenario Pay estimate=three"
+    Then I should see an error message informing me "Field 'estimate' must be a number"
+
+  Scenario: Keys only apply to some kinds of object
+    When I run "metadata add --story Checkout estimate:=3"
+    Then I should see an error message informing me "Field 'estimate' cannot be set on a story"
+
+  Scenario: Check existing metadata against the schema
+    Given I run "metadata add --scenario Pay status=done"
+    When I run "metadata check"
+    Then I should see the following problems:
+      """
+      story Checkout (features/checkout.feature): Field 'status' is required
+      story story1 (features/story1.feature): Field 'status' is required
+      """
+    And I should see an error message informing me "2 objects break the metadata schema"
+
+  Scenario: The schema must be well formed
+    Given I have a file called ".specschema.json" with the following content:
+      """
+      {"keys": {"status": {"type": "colour"}}}
+      """
+    When I run "metadata check"
+    Then I should see an error message informing me "invalid metadata schema for status: unknown type colour"
@@ -0,0 +1,67 @@
+Feature: Update and remove metadata
+  As a Developer
+  I want to change or remove metadata I have added
+  So that it reflects the current state of the project without losing its history
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+          When I pay for my order
+      """
+    And I run "metadata add --scenario Pay status=draft"
+    And I run "metadata add --scenario Pay owner=alice"
+
+  Scenario: Set replaces the value of a key
+    When I run "metadata set --scenario Pay status=done"
+    Then I should see no errors
+    When I run "metadata list --scenario Pay"
+    Then I should not see "draft"
+    And I should see the following:
+      """
+      status: done
+      owner : alice
+      """
+
+  Scenario: Remove hides a key
+    When I run "metadata rm --scenario Pay owner"
+    Then I should see no errors
+    When I run "metadata list --scenario Pay"
+    Then I should not see "alice"
+    And I should see the following:
+      """
+      status: draft
+      """
+
+  Scenario: Removing a key that has no value
+    When I run "metadata rm --scenario Pay reviewer"
+    Then I should see an error message informing me "no metadata named reviewer"
+
+  Scenario: The history keeps every value
+    Given I run "metadata set --scenario Pay status=done"
+    And I run "metadata rm --scenario Pay owner"
+    When I run "metadata list --scenario Pay --history"
+    Then I should see the following:
+      """
+      status: draft
+      owner: alice
+      status: done
+      owner (removed)
+      """
+
+  Scenario: Edit metadata in an editor
+    Given my editor replaces "draft" with "review"
+    When I run "metadata edit --scenario Pay"
+    Then I should see no errors
+    When I run "metadata list --scenario Pay"
+    Then I should not see "draft"
+    And I should see the following:
+      """
+      status: review
+      owner : alice
+      """
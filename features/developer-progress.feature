@@ -0,0 +1,93 @@
+Feature: Track the progress of scenarios
+  As a Developer
+  I want to move scenarios through a workflow
+  So that I can see how far along each story and actor is
+
+  Progress is recorded as "spec.progress" metadata, which only spec progress
+  set can change. Metadata that projects already call "progress" is left as
+  it is. Projects with a metadata schema need to allow "spec.progress" on
+  scenarios.
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/shopper.actor" with the following content:
+      """
+      Actor: Shopper
+      """
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+        As a Shopper
+        I want to pay for my basket
+
+        Scenario: Pay
+          Given I have a card
+
+        Scenario: Refund
+          Given I have paid
+      """
+
+  Scenario: Scenarios start in the first state of the workflow
+    When I run "progress"
+    Then I should see no errors
+    And I should see the following:
+      """
+      STORY     todo  in-progress  review  done
+      Checkout  2     0            0       0
+      """
+    And I should see the following:
+      """
+      ACTOR    todo  in-progress  review  done
+      Shopper  2     0            0       0
+      """
+
+  Scenario: Move a scenario along the workflow
+    When I run "progress set Checkout/Pay in-progress"
+    And I run "progress set Checkout/Pay review"
+    Then I should see no errors
+    When I run "progress"
+    Then I should see the following:
+      """
+      Checkout  1     0            1       0
+      """
+
+  Scenario: Transitions outside the workflow are refused
+    When I run "progress set Pay done"
+    Then I should see an error message informing me "cannot move from todo to done, expected one of in-progress"
+
+  Scenario: The workflow can be configured
+    Given I run "config set project.workflow=open>closed"
+    When I run "progress set Pay closed"
+    And I run "progress"
+    Then I should see the following:
+      """
+      STORY     open  closed
+      Checkout  1     1
+      """
+
+  Scenario: Progress is kept in the scenario's metadata history
+    When I run "progress set Pay in-progress"
+    And I run "metadata list --scenario Pay --history"
+    Then I should see the following:
+      """
+      spec.progress: in-progress  by Speck Stack <dev@specstack.io>
+      """
+
+  Scenario: Progress can't be set as metadata
+    When I run "metadata set --scenario Pay spec.progress=done"
+    Then I should see an error message informing me "Field 'spec.progress' can only be changed with spec progress set"
+
+  Scenario: Metadata called progress is left to the project
+    When I run "metadata set --scenario Pay progress=done"
+    Then I should see no errors
+
+  Scenario: Progress is checked against the metadata schema
+    Given I have a file called ".specschema.json" with the following content:
+      """
+      {"keys": {"spec.progress": {"type": "string", "enum": ["todo", "in-progress"], "appliesTo": ["scenario"]}}}
+      """
+    When I run "progress set Pay in-progress"
+    Then I should see no errors
+    When I run "progress set Pay review"
+    Then I should see an error message informing me "Field 'spec.progress' must be one of todo, in-progress"
@@ -24,6 +24,10 @@ Feature: View and change project configuration
       project.featuresdir=./features
       project.pushingmode=auto
       project.pullingmode=semi-auto
+      project.dialect=en
+      project.include=
+      project.exclude=
+      project.workflow=todo>in-progress>review>done,review>in-progress
       """
 
   Scenario: Attempt to get non-existing config key
@@ -0,0 +1,96 @@
+Feature: Query the specification
+  As a Developer
+  I want to select stories and scenarios with a query
+  So I can find the parts of my specification I care about
+
+  Scenario: List scenarios matching a query
+    Given I have a configured project directory
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      @api
+      Feature: StoryA
+        Scenario: AlphaOne
+          When I do something
+        @wip
+        Scenario: AlphaTwo
+          When I do something else
+          Then something happens
+      """
+    When I run "query tag:@api and steps>1"
+    Then I should see the following:
+      """
+      StoryA  AlphaTwo  features/storyA.feature:6
+      """
+
+  Scenario: List stories matching a query as JSON
+    Given I have a configured project directory
+    And I have a file called "features/storyA.feature" with the following content:
+      """
+      Feature: StoryA
+        Scenario: AlphaOne
+          When I do something
+      """
+    And I have a file called "features/storyB.feature" with the following content:
+      """
+      Feature: StoryB
+        Scenario: BetaOne
+          When I do something
+      """
+    When I run "query --type story --format json story=StoryB"
+    Then I should see the following:
+      """
+        "story": "StoryB",
+        "file": "features/storyB.feature"
+      """
+
+  Scenario: Invalid query
+    Given I have a configured project directory
+    And I have a story called "storyA"
+    When I run "query steps>lots"
+    Then I should see an error message informing me "steps must be compared with a number, got 'lots'"
+
+  Scenario: Ignored stories are left out of the specification
+    Given I have a configured project directory
+    And I have a file called "features/.specignore" with the following content:
+      """
+      drafts/
+      """
+    And I have a file called "features/drafts/draft.feature" with the following content:
+      """
+      Feature: Draft
+        Scenario: DraftOne
+          When I do something
+      """
+    When I run "metadata add --story draft key=value"
+    Then I should see an error message informing me "no story matching draft"
+
+  Scenario: The project directory can have an ignore file
+    Given I have a configured project directory
+    And I have a file called ".specignore" with the following content:
+      """
+      features/drafts/
+      """
+    And I have a file called "features/drafts/draft.feature" with the following content:
+      """
+      Feature: Draft
+        Scenario: DraftOne
+          When I do something
+      """
+    When I run "metadata add --story draft key=value"
+    Then I should see an error message informing me "no story matching draft"
+
+  Scenario: Stories are limited to the included patterns
+    Given I have a configured project directory
+    And I have a file called "features/api/login.feature" with the following content:
+      """
+      Feature: Login
+        Scenario: LoginOne
+          When I log in
+      """
+    And I run "config set project.include=api/**"
+    When I run "query --type story"
+    Then I should see the following:
+      """
+      Login  features/api/login.feature
+      """
+    And I should not see "story1"
@@ -0,0 +1,84 @@
+Feature: Anchor metadata to stable scenario IDs
+  As a Developer
+  I want to give scenarios stable IDs
+  So their metadata survives rewording them
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+
+  Scenario: Metadata follows a scenario ID when the scenario is reworded
+    Given I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        @id:pay-by-card
+        Scenario: Pay by card
+          Given I have a card
+      """
+    And I run "metadata add --scenario @id:pay-by-card key=value"
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        @id:pay-by-card
+        Scenario: Settle the bill with a credit card
+          Given I have a credit card
+          And the card has not expired
+      """
+    When I run "metadata list --scenario @id:pay-by-card"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Assign IDs to scenarios without one
+    Given I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay by cash
+          Given I have cash
+      """
+    And I run "metadata add --scenario cash key=value"
+    When I run "ids assign"
+    Then I should see the following:
+      """
+      @id:pay-by-cash: Pay by cash (features/checkout.feature:3)
+      """
+    When I run "metadata list --scenario @id:pay-by-cash"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Metadata moved to a new ID is pushed in automatic mode
+    Given I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay by cash
+          Given I have cash
+      """
+    And I run "metadata add --scenario cash key=value"
+    And I run "config set project.pushingmode=auto"
+    When I run "ids assign"
+    And I run "status"
+    Then I should see the following:
+      """
+      1 metadata change waiting to be pushed to origin:
+      """
+
+  Scenario: Duplicate scenario IDs
+    Given I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        @id:pay
+        Scenario: Pay by card
+
+        @id:pay
+        Scenario: Pay by cash
+      """
+    When I run "ids check"
+    Then I should see an error message informing me "duplicate scenario id pay: features/checkout.feature:4, features/checkout.feature:7"
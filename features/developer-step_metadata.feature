@@ -0,0 +1,48 @@
+Feature: Step metadata
+  As a Developer
+  I want to attach metadata to individual steps
+  So I can note what a step touches or where it is defined
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+          When I pay for my order
+          Then the payments sandbox is charged
+      """
+
+  Scenario: Add metadata to a step by its index
+    When I run "metadata add --scenario Pay --step 3 key=value"
+    Then I should see no errors
+    When I run "metadata list --scenario Pay --step 3"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Steps need a scenario
+    When I run "metadata add --step 3 key=value"
+    Then I should see an error message informing me "specify the scenario of the step with --scenario"
+
+  Scenario: Step metadata is carried along with its scenario
+    Given I make a commit
+    And I run "metadata add --scenario Pay --step 3 key=value"
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+          When I pay for my order
+          Then the payments sandbox is charged once
+      """
+    When I run "metadata list --scenario Pay --step 3"
+    Then I should see the following:
+      """
+      key: value
+      """
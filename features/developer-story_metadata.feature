@@ -0,0 +1,77 @@
+Feature: Keep story metadata when feature files change
+  As a Developer
+  I want story metadata to follow the story rather than its file
+  So my notes survive adding scenarios, reformatting and renaming
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+        As a customer
+        I want to pay for my order
+
+        Scenario: Pay by card
+          Given I have a card
+      """
+
+  Scenario: Story metadata survives adding a scenario and reformatting
+    Given I run "metadata add --story checkout key=value"
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+          As a customer
+          I want to pay for my order
+
+          Scenario: Pay by card
+              Given I have a card
+
+          Scenario: Pay by cash
+              Given I have cash
+      """
+    When I run "metadata list --story checkout"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Story metadata follows a renamed story
+    Given I make a commit
+    And I run "metadata add --story checkout key=value"
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Paying
+        As a customer
+        I want to pay for my order and get a receipt
+
+        Scenario: Pay by card
+          Given I have a card
+      """
+    When I run "metadata list --story paying"
+    Then I should see the following:
+      """
+      key: value
+      """
+
+  Scenario: Story metadata follows a story ID
+    Given I have a file called "features/checkout.feature" with the following content:
+      """
+      @id:checkout
+      Feature: Checkout
+      """
+    And I run "metadata add --story checkout key=value"
+    And I have a file called "features/renamed.feature" with the following content:
+      """
+      @id:checkout
+      Feature: Something else entirely
+      """
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Unrelated
+      """
+    When I run "metadata list --story renamed"
+    Then I should see the following:
+      """
+      key: value
+      """
@@ -64,6 +64,46 @@ Feature: Synchronise metadata
     When I add some metadata
     Then I should see an error message informing me "set git remote 'origin' first"
 
+  Scenario: Failed automatic pushes are queued
+    Given I have a git-initialised project directory
+    And I have set the pushing mode to automatic
+    But I have not set a git remote
+    When I add some metadata
+    And I run "status"
+    Then I should see the following:
+      """
+      1 metadata change waiting to be pushed to origin:
+      """
+    And I should see the following:
+      """
+      key1
+      The last push failed: set git remote 'origin' first
+      """
+
+  Scenario: Queued pushes that still fail are reported
+    Given I have a git-initialised project directory
+    And I have set the pushing mode to automatic
+    But I have not set a git remote
+    When I add some metadata
+    And I run "config list"
+    Then I should see a warning message informing me "Could not push metadata changes that had been waiting: set git remote 'origin' first"
+
+  Scenario: Queued pushes are retried by git hooks
+    Given I have a git-initialised project directory
+    And I have set the pushing mode to automatic
+    But I have not set a git remote
+    When I add some metadata
+    And I run "git-hook exec post-commit"
+    Then I should see a warning message informing me "Could not push metadata changes that had been waiting: set git remote 'origin' first"
+
+  Scenario: Nothing is queued when every push succeeds
+    Given I have a git-initialised project directory
+    When I run "status"
+    Then I should see the following:
+      """
+      No metadata changes waiting to be pushed
+      """
+
   Scenario: Unexpected error for manual pull
     Given I have a properly configured project directory
     But The remote git server isn't responding properly
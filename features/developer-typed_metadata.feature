@@ -0,0 +1,39 @@
+Feature: Typed metadata
+  As a Developer
+  I want to give metadata numbers, booleans, dates, lists and objects
+  So that reports can work with values without parsing them by hand
+
+  Background:
+    Given I have a configured project directory
+    And the pushing mode is not set to automatic
+    And I have a file called "features/checkout.feature" with the following content:
+      """
+      Feature: Checkout
+
+        Scenario: Pay
+          Given I have a card
+      """
+
+  Scenario: Add typed metadata with JSON values
+    When I run "metadata add --scenario Pay estimate:=3 done:=false sizes:=[1,2]"
+    Then I should see no errors
+    When I run "metadata list --scenario Pay"
+    Then I should see the following:
+      """
+      done    : false
+      estimate: 3
+      sizes   : [1,2]
+      """
+
+  Scenario: Typed and plain values can be mixed
+    When I run "metadata set --scenario Pay estimate:=5 owner=alice"
+    And I run "metadata list --scenario Pay"
+    Then I should see the following:
+      """
+      estimate: 5
+      owner   : alice
+      """
+
+  Scenario: Typed values must be valid JSON
+    When I run "metadata add --scenario Pay estimate:=three"
+    Then I should see an error message informing me "invalid JSON value for estimate"
@@ -3,8 +3,9 @@ module github.com/endiangroup/specstack
 go 1.15
 
 require (
-	github.com/cucumber/cucumber-messages-go/v2 v2.1.2 // indirect
+	github.com/cucumber/cucumber-messages-go/v2 v2.1.2
 	github.com/cucumber/gherkin-go v0.0.0-20181031235610-f732235a1dbe
 	github.com/endiangroup/pretty-formatter-go v0.0.0-20200412175208-99fc86d6539f
 	github.com/gogo/protobuf v1.3.1 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
@@ -1,7 +1,9 @@
 package metadata
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
 	"time"
 )
 
@@ -14,11 +16,18 @@ func assertHeaders(entry *Entry) error {
 }
 
 func Add(storer Storer, key io.Reader, entries ...*Entry) error {
+	// Storing an entry consumes the key, so it's buffered to be read again
+	// for each one.
+	content, err := ioutil.ReadAll(key)
+	if err != nil {
+		return err
+	}
+
 	for _, entry := range entries {
 		if err := assertHeaders(entry); err != nil {
 			return err
 		}
-		if err := storer.StoreMetadata(key, entry); err != nil {
+		if err := storer.StoreMetadata(bytes.NewBuffer(content), entry); err != nil {
 			return err
 		}
 	}
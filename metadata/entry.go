@@ -1,15 +1,32 @@
 package metadata
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 )
 
+// An Entry is a single value recorded for a named piece of metadata. Later
+// entries with the same name supersede earlier ones, and a Deleted entry is a
+// tombstone that removes the name until it is given a new value.
+//
+// Data holds the JSON value of a typed entry, see NewTypedKeyValue. Author
+// and Commit record who wrote the entry and the commit it was written against.
+// Entries written before they were recorded leave them empty.
 type Entry struct {
 	CreatedAt time.Time
 	Name      string
 	Value     string
+	Data      json.RawMessage `json:",omitempty"`
+	Deleted   bool            `json:",omitempty"`
+	Author    string          `json:",omitempty"`
+	Commit    string          `json:",omitempty"`
 }
 
+// DiscussionName names the entries that hold posts in discussions about an
+// object, rather than a piece of its metadata.
+const DiscussionName = "discussion"
+
 func New() *Entry {
 	return &Entry{}
 }
@@ -20,3 +37,29 @@ func NewKeyValue(key, value string) *Entry {
 	e.Value = value
 	return e
 }
+
+// AuthorName returns the name part of the entry's author, without the email
+// address.
+func (e *Entry) AuthorName() string {
+	if i := strings.Index(e.Author, " <"); i >= 0 {
+		return e.Author[:i]
+	}
+	return e.Author
+}
+
+// WrittenBy reports whether the entry's author matches the given name or email
+// address, ignoring case. Entries without an author match nothing.
+func (e *Entry) WrittenBy(author string) bool {
+	if e.Author == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(e.Author), strings.ToLower(author))
+}
+
+// NewTombstone creates an entry that removes the named metadata.
+func NewTombstone(key string) *Entry {
+	e := New()
+	e.Name = key
+	e.Deleted = true
+	return e
+}
@@ -0,0 +1,29 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AnEntryIsWrittenByItsAuthor(t *testing.T) {
+	entry := NewKeyValue("A", "1")
+	require.False(t, entry.WrittenBy("jane"))
+	require.Equal(t, "", entry.AuthorName())
+
+	entry.Author = "Jane Doe <jane@example.com>"
+	require.True(t, entry.WrittenBy("jane"))
+	require.True(t, entry.WrittenBy("JANE@example.com"))
+	require.False(t, entry.WrittenBy("john"))
+	require.Equal(t, "Jane Doe", entry.AuthorName())
+}
+
+func Test_AnEntryWithoutAnAuthorCanBeRead(t *testing.T) {
+	entry := Entry{}
+	require.Nil(t, json.Unmarshal([]byte(`{"CreatedAt":"2018-01-01T00:00:00Z","Name":"A","Value":"1"}`), &entry))
+
+	require.Equal(t, "A", entry.Name)
+	require.Equal(t, "", entry.Author)
+	require.Equal(t, "", entry.Commit)
+}
@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PendingPush is a metadata change that was recorded locally but could not be
+// pushed to the remote.
+type PendingPush struct {
+	QueuedAt time.Time
+	Names    []string
+	Reason   string `json:",omitempty"`
+}
+
+// NewPendingPush describes the entries of a change whose push failed.
+func NewPendingPush(reason error, entries ...*Entry) PendingPush {
+	names := []string{}
+	seen := map[string]struct{}{}
+	for _, entry := range entries {
+		if _, exists := seen[entry.Name]; !exists {
+			seen[entry.Name] = struct{}{}
+			names = append(names, entry.Name)
+		}
+	}
+
+	push := PendingPush{QueuedAt: time.Now(), Names: names}
+	if reason != nil {
+		push.Reason = reason.Error()
+	}
+	return push
+}
+
+/*
+Outbox keeps the metadata changes that still need pushing in a local file, one
+JSON object per line, so that they survive until a later push succeeds. The
+metadata themselves are already in the repository; the outbox only records
+that the remote is behind.
+*/
+type Outbox struct {
+	path string
+}
+
+func NewOutbox(path string) *Outbox {
+	return &Outbox{path: path}
+}
+
+// Queue adds a change to the outbox.
+func (o *Outbox) Queue(push PendingPush) error {
+	if err := os.MkdirAll(filepath.Dir(o.path), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(o.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(push)
+}
+
+// Pending returns the queued changes, oldest first.
+func (o *Outbox) Pending() ([]PendingPush, error) {
+	pending := []PendingPush{}
+
+	file, err := os.Open(o.path)
+	if os.IsNotExist(err) {
+		return pending, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var push PendingPush
+		if err := json.Unmarshal(scanner.Bytes(), &push); err != nil {
+			return nil, err
+		}
+		pending = append(pending, push)
+	}
+
+	return pending, scanner.Err()
+}
+
+// Clear empties the outbox, once everything in it has been pushed.
+func (o *Outbox) Clear() error {
+	if err := os.Remove(o.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package metadata
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AnOutboxKeepsQueuedPushesUntilCleared(t *testing.T) {
+	dir, err := ioutil.TempDir("", "specstack-outbox")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	outbox := NewOutbox(filepath.Join(dir, "specstack", "outbox"))
+
+	pending, err := outbox.Pending()
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+
+	require.Nil(t, outbox.Queue(NewPendingPush(
+		errors.New("offline"),
+		NewKeyValue("owner", "alice"),
+		NewKeyValue("team", "payments"),
+		NewKeyValue("owner", "bob"),
+	)))
+	require.Nil(t, outbox.Queue(NewPendingPush(nil, NewTombstone("team"))))
+
+	pending, err = NewOutbox(filepath.Join(dir, "specstack", "outbox")).Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, []string{"owner", "team"}, pending[0].Names)
+	assert.Equal(t, "offline", pending[0].Reason)
+	assert.Equal(t, []string{"team"}, pending[1].Names)
+	assert.Empty(t, pending[1].Reason)
+
+	require.Nil(t, outbox.Clear())
+	require.Nil(t, outbox.Clear())
+
+	pending, err = outbox.Pending()
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+}
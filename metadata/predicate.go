@@ -0,0 +1,170 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The operators a Predicate can compare metadata with.
+const (
+	OperatorExists    = ""
+	OperatorMissing   = "!"
+	OperatorEqual     = "="
+	OperatorNotEqual  = "!="
+	OperatorMatches   = "~"
+	OperatorGreater   = ">"
+	OperatorGreaterEq = ">="
+	OperatorLess      = "<"
+	OperatorLessEq    = "<="
+)
+
+/*
+A Predicate tests the current value of a piece of metadata. Written out,
+predicates look like:
+
+	owner          owner is set
+	!owner         owner is not set
+	owner=alice    owner is alice (also !=)
+	owner~^al      owner matches a regular expression
+	estimate>3     estimate is a number greater than 3 (also >=, <, <=)
+
+Values are compared as text, except in numeric comparisons. The text of a
+typed string value is the string itself rather than its JSON.
+*/
+type Predicate struct {
+	Key      string
+	Operator string
+	Value    string
+
+	pattern *regexp.Regexp
+	number  float64
+}
+
+// NewPredicate creates a predicate, checking that regular expressions and
+// numbers are valid.
+func NewPredicate(key, operator, value string) (*Predicate, error) {
+	p := &Predicate{Key: key, Operator: operator, Value: value}
+
+	var err error
+	switch operator {
+	case OperatorExists, OperatorMissing, OperatorEqual, OperatorNotEqual:
+	case OperatorMatches:
+		if p.pattern, err = regexp.Compile(value); err != nil {
+			return nil, fmt.Errorf("invalid pattern for %s: %s", key, err)
+		}
+	case OperatorGreater, OperatorGreaterEq, OperatorLess, OperatorLessEq:
+		if p.number, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("%s must be compared with a number, got '%s'", key, value)
+		}
+	default:
+		return nil, fmt.Errorf("unknown operator '%s'", operator)
+	}
+
+	return p, nil
+}
+
+// ParsePredicate parses a written out predicate, such as "estimate>3".
+func ParsePredicate(expression string) (*Predicate, error) {
+	if strings.HasPrefix(expression, OperatorMissing) {
+		return newKeyPredicate(expression[1:], OperatorMissing)
+	}
+
+	i := strings.IndexAny(expression, "=!~<>")
+	if i < 0 {
+		return newKeyPredicate(expression, OperatorExists)
+	}
+
+	operator := expression[i : i+1]
+	if strings.ContainsAny(operator, "!<>") && strings.HasPrefix(expression[i+1:], "=") {
+		operator += "="
+	}
+	if operator == OperatorMissing {
+		return nil, fmt.Errorf("invalid metadata predicate '%s'", expression)
+	}
+
+	key := expression[:i]
+	if key == "" {
+		return nil, fmt.Errorf("invalid metadata predicate '%s'", expression)
+	}
+
+	return NewPredicate(key, operator, expression[i+len(operator):])
+}
+
+func newKeyPredicate(key, operator string) (*Predicate, error) {
+	if key == "" {
+		return nil, fmt.Errorf("invalid metadata predicate '%s'", operator)
+	}
+	return NewPredicate(key, operator, "")
+}
+
+// String writes the predicate out in the form ParsePredicate reads.
+func (p *Predicate) String() string {
+	if p.Operator == OperatorMissing {
+		return p.Operator + p.Key
+	}
+	return p.Key + p.Operator + p.Value
+}
+
+// Match reports whether the current metadata of an object, as returned by
+// ReadAll, matches the predicate.
+func (p *Predicate) Match(entries []*Entry) bool {
+	var entry *Entry
+	for _, e := range entries {
+		if e.Name == p.Key {
+			entry = e
+		}
+	}
+
+	switch p.Operator {
+	case OperatorExists:
+		return entry != nil
+	case OperatorMissing:
+		return entry == nil
+	case OperatorNotEqual:
+		return entry == nil || entry.text() != p.Value
+	}
+
+	if entry == nil {
+		return false
+	}
+
+	switch p.Operator {
+	case OperatorEqual:
+		return entry.text() == p.Value
+	case OperatorMatches:
+		return p.pattern.MatchString(entry.text())
+	}
+
+	return p.compare(entry)
+}
+
+func (p *Predicate) compare(entry *Entry) bool {
+	number, err := entry.Number()
+	if err != nil && !entry.Typed() {
+		number, err = strconv.ParseFloat(strings.TrimSpace(entry.Value), 64)
+	}
+	if err != nil {
+		return false
+	}
+
+	switch p.Operator {
+	case OperatorGreater:
+		return number > p.number
+	case OperatorGreaterEq:
+		return number >= p.number
+	case OperatorLess:
+		return number < p.number
+	}
+	return number <= p.number
+}
+
+// text returns the entry's value as text, unquoting typed strings.
+func (e *Entry) text() string {
+	var s string
+	if e.Typed() && e.Decode(&s) == nil {
+		return s
+	}
+	return e.Value
+}
@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_APredicateMatchesMetadata(t *testing.T) {
+	estimate, err := NewTypedKeyValue("estimate", "5")
+	require.Nil(t, err)
+	owner, err := NewTypedKeyValue("owner", `"alice"`)
+	require.Nil(t, err)
+
+	entries := []*Entry{estimate, owner, NewKeyValue("size", "12")}
+
+	for _, test := range []struct {
+		predicate string
+		match     bool
+	}{
+		{"owner", true},
+		{"reviewer", false},
+		{"!reviewer", true},
+		{"!owner", false},
+		{"owner=alice", true},
+		{"owner=bob", false},
+		{"owner!=bob", true},
+		{"reviewer!=bob", true},
+		{"owner~^al", true},
+		{"owner~^bo", false},
+		{"estimate>3", true},
+		{"estimate>=5", true},
+		{"estimate<5", false},
+		{"size<=12", true},
+		{"owner>1", false},
+		{"reviewer>1", false},
+	} {
+		t.Run(test.predicate, func(t *testing.T) {
+			predicate, err := ParsePredicate(test.predicate)
+			require.Nil(t, err)
+			require.Equal(t, test.predicate, predicate.String())
+			require.Equal(t, test.match, predicate.Match(entries))
+		})
+	}
+}
+
+func Test_APredicateMustBeValid(t *testing.T) {
+	for _, test := range []struct {
+		predicate string
+		err       error
+	}{
+		{"", fmt.Errorf("invalid metadata predicate ''")},
+		{"!", fmt.Errorf("invalid metadata predicate '!'")},
+		{"=alice", fmt.Errorf("invalid metadata predicate '=alice'")},
+		{"owner!alice", fmt.Errorf("invalid metadata predicate 'owner!alice'")},
+		{"estimate>many", fmt.Errorf("estimate must be compared with a number, got 'many'")},
+	} {
+		t.Run(test.predicate, func(t *testing.T) {
+			_, err := ParsePredicate(test.predicate)
+			require.Equal(t, test.err, err)
+		})
+	}
+
+	_, err := ParsePredicate("owner~(")
+	require.NotNil(t, err)
+}
@@ -1,6 +1,22 @@
 package metadata
 
-import io "io"
+import (
+	"fmt"
+	io "io"
+	"strings"
+)
+
+// The formats that metadata can be printed and scanned in.
+const (
+	FormatPlain = "plain"
+	FormatFull  = "full"
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// Formats lists the formats understood by NewPrintScanner.
+var Formats = []string{FormatPlain, FormatFull, FormatTable, FormatJSON, FormatYAML}
 
 type Printer interface {
 	Print(io.Writer, []*Entry) error
@@ -14,3 +30,21 @@ type PrintScanner interface {
 	Printer
 	Scanner
 }
+
+// NewPrintScanner returns the PrintScanner for the named format.
+func NewPrintScanner(format string) (PrintScanner, error) {
+	switch format {
+	case FormatPlain:
+		return NewPlaintextPrintscanner(), nil
+	case FormatFull:
+		return NewFullPlaintextPrintscanner(), nil
+	case FormatTable:
+		return NewTablePrintScanner(), nil
+	case FormatJSON:
+		return NewJSONPrintScanner(), nil
+	case FormatYAML:
+		return NewYAMLPrintScanner(), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
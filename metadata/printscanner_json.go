@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	io "io"
+)
+
+// A JSONPrintScanner prints entries as an indented JSON array, with every
+// field of each entry.
+type JSONPrintScanner struct {
+}
+
+func NewJSONPrintScanner() PrintScanner {
+	return &JSONPrintScanner{}
+}
+
+func (p *JSONPrintScanner) Print(writer io.Writer, entries []*Entry) error {
+	if entries == nil {
+		entries = []*Entry{}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+func (p *JSONPrintScanner) Scan(reader io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+	if err := json.NewDecoder(reader).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	return entries, valuesFromData(entries)
+}
+
+// valuesFromData sets the value of each typed entry from its JSON, which is
+// what formats that print both treat as the value.
+func valuesFromData(entries []Entry) error {
+	for i := range entries {
+		if !entries[i].Typed() {
+			continue
+		}
+
+		data := &bytes.Buffer{}
+		if err := json.Compact(data, entries[i].Data); err != nil {
+			return err
+		}
+		entries[i].Data = json.RawMessage(data.Bytes())
+		entries[i].Value = data.String()
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AJSONPrintScannerKeepsEveryField(t *testing.T) {
+	entries := []*Entry{
+		{
+			CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Name:      "estimate",
+			Value:     "5",
+			Data:      json.RawMessage("5"),
+			Author:    "Jane Doe <jane@example.com>",
+			Commit:    "0123456789abcdef",
+		},
+		{Name: "owner", Deleted: true},
+	}
+
+	printScanner := NewJSONPrintScanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printScanner.Print(buf, entries))
+
+	read, err := printScanner.Scan(buf)
+	require.Nil(t, err)
+	require.Equal(t, []Entry{*entries[0], *entries[1]}, read)
+}
+
+func Test_AJSONPrintScannerPrintsNoEntriesAsAnEmptyArray(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, NewJSONPrintScanner().Print(buf, nil))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func Test_AJSONPrintScannerTakesTypedValuesFromTheirData(t *testing.T) {
+	read, err := NewJSONPrintScanner().Scan(strings.NewReader(`[{"Name": "tags", "Data": [ "a", "b" ]}]`))
+	require.Nil(t, err)
+	require.Equal(t, []Entry{{Name: "tags", Value: `["a","b"]`, Data: json.RawMessage(`["a","b"]`)}}, read)
+}
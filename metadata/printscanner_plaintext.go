@@ -9,13 +9,31 @@ import (
 
 const lineLength = 100
 
+// authorPrefix and authorSuffix surround the author printed after a value.
+const (
+	authorPrefix = "  (by "
+	authorSuffix = ")"
+)
+
+// nameSeparator separates a name from its value.
+const nameSeparator = ": "
+
+// A PlaintextPrintScanner prints one "name: value" line per entry, truncating
+// long values unless it is full.
 type PlaintextPrintScanner struct {
+	full bool
 }
 
 func NewPlaintextPrintscanner() PrintScanner {
 	return &PlaintextPrintScanner{}
 }
 
+// NewFullPlaintextPrintscanner returns a PlaintextPrintScanner that prints
+// values in full.
+func NewFullPlaintextPrintscanner() PrintScanner {
+	return &PlaintextPrintScanner{full: true}
+}
+
 func (p *PlaintextPrintScanner) Print(writer io.Writer, entries []*Entry) error {
 	longest := 0
 
@@ -26,11 +44,27 @@ func (p *PlaintextPrintScanner) Print(writer io.Writer, entries []*Entry) error
 	}
 
 	for _, entry := range entries {
+		author := ""
+		if name := entry.AuthorName(); name != "" {
+			author = authorPrefix + name + authorSuffix
+		} else if p.endsWithAuthor(entry.Value) {
+			// An empty author stops the end of the value being read back as
+			// its author.
+			author = authorPrefix + authorSuffix
+		}
+
+		value := entry.Value
+		if !p.full {
+			value = p.truncate(value, lineLength-longest-len(nameSeparator))
+		}
+
 		if _, err := fmt.Fprintf(
 			writer,
-			"%s: %s\n",
+			"%s%s%s%s\n",
 			p.padRight(entry.Name, " ", longest),
-			p.truncate(entry.Value, lineLength-longest-2),
+			nameSeparator,
+			value,
+			author,
 		); err != nil {
 			return err
 		}
@@ -39,20 +73,22 @@ func (p *PlaintextPrintScanner) Print(writer io.Writer, entries []*Entry) error
 	return nil
 }
 
+// Scan reads entries back from printed lines. Values that were truncated when
+// printed can't be read back, so are an error rather than being cut short.
 func (p *PlaintextPrintScanner) Scan(reader io.Reader) ([]Entry, error) {
 	entries := []Entry{}
 
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		parts := strings.Split(scanner.Text(), ":")
-
-		if len(parts) > 1 {
-			e := Entry{
-				Name:  strings.TrimSpace(parts[0]),
-				Value: strings.TrimSpace(strings.Join(parts[1:], ":")),
-			}
-			entries = append(entries, e)
+		line := p.trimAuthor(scanner.Text())
+		name, value, ok := p.split(line)
+		if !ok {
+			continue
 		}
+		if !p.full && p.truncated(line) {
+			return nil, fmt.Errorf("the value of %s was truncated when printed, so can't be read back", name)
+		}
+		entries = append(entries, Entry{Name: name, Value: strings.TrimSpace(value)})
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
@@ -61,6 +97,42 @@ func (p *PlaintextPrintScanner) Scan(reader io.Reader) ([]Entry, error) {
 	return entries, nil
 }
 
+// split separates a line into a name and value. Names can contain colons, so
+// the value starts after the first colon followed by a space, falling back to
+// the first colon for lines written without one.
+func (p *PlaintextPrintScanner) split(line string) (string, string, bool) {
+	i := strings.Index(line, nameSeparator)
+	if i < 0 {
+		if i = strings.Index(line, ":"); i < 0 {
+			return "", "", false
+		}
+	}
+
+	name := strings.TrimSpace(line[:i])
+	if name == "" {
+		return "", "", false
+	}
+	return name, line[i+1:], true
+}
+
+// trimAuthor removes the author that Print adds after a value.
+func (p *PlaintextPrintScanner) trimAuthor(line string) string {
+	if p.endsWithAuthor(line) {
+		line = line[:strings.LastIndex(line, authorPrefix)]
+	}
+	return line
+}
+
+func (p *PlaintextPrintScanner) endsWithAuthor(value string) bool {
+	return strings.Contains(value, authorPrefix) && strings.HasSuffix(value, authorSuffix)
+}
+
+// truncated reports whether a line, without its author, is one that Print
+// truncated, which makes it exactly as long as a line can be.
+func (p *PlaintextPrintScanner) truncated(line string) bool {
+	return len(line) == lineLength && strings.HasSuffix(line, "...")
+}
+
 func (p *PlaintextPrintScanner) padRight(str, pad string, length int) string {
 	for {
 		str += pad
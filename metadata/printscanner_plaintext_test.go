@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/endiangroup/snaptest"
@@ -32,9 +33,69 @@ func Test_APlaintextPrintScannerCanPrint(t *testing.T) {
 		snaptest.Snapshot(t, buf.String())
 	})
 
-	t.Run("Can read", func(t *testing.T) {
-		entries, err := printer.Scan(buf)
-		require.Nil(t, err)
-		snaptest.Snapshot(t, entries)
+	t.Run("Can't read values it truncated", func(t *testing.T) {
+		_, err := printer.Scan(buf)
+		require.NotNil(t, err)
 	})
 }
+
+func Test_APlaintextPrintScannerPrintsAuthors(t *testing.T) {
+	entries := []*Entry{
+		{Name: "A", Value: "1", Author: "Jane Doe <jane@example.com>"},
+		{Name: "B", Value: "2"},
+	}
+
+	printer := NewPlaintextPrintscanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printer.Print(buf, entries))
+	require.Equal(t, "A: 1  (by Jane Doe)\nB: 2\n", buf.String())
+
+	read, err := printer.Scan(buf)
+	require.Nil(t, err)
+	require.Equal(t, []Entry{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}, read)
+}
+
+func Test_APlaintextPrintScannerKeepsValuesThatLookLikeAuthors(t *testing.T) {
+	entries := []*Entry{
+		{Name: "A", Value: "reviewed  (by Jane)"},
+		{Name: "B", Value: "reviewed  (by Jane)", Author: "John Doe <john@example.com>"},
+	}
+
+	printer := NewPlaintextPrintscanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printer.Print(buf, entries))
+	require.Equal(t, "A: reviewed  (by Jane)  (by )\nB: reviewed  (by Jane)  (by John Doe)\n", buf.String())
+
+	read, err := printer.Scan(buf)
+	require.Nil(t, err)
+	require.Equal(t, []Entry{{Name: "A", Value: "reviewed  (by Jane)"}, {Name: "B", Value: "reviewed  (by Jane)"}}, read)
+}
+
+func Test_AFullPlaintextPrintScannerDoesNotTruncate(t *testing.T) {
+	value := strings.Repeat("a", 2*lineLength)
+	entries := []*Entry{{Name: "A", Value: value}}
+
+	printer := NewFullPlaintextPrintscanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printer.Print(buf, entries))
+	require.Equal(t, "A: "+value+"\n", buf.String())
+}
+
+func Test_APlaintextPrintScannerScansNamesWithColons(t *testing.T) {
+	entries := []*Entry{
+		{Name: "link:docs", Value: "https://example.com"},
+		{Name: "empty", Value: ""},
+	}
+
+	printer := NewPlaintextPrintscanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printer.Print(buf, entries))
+
+	read, err := printer.Scan(strings.NewReader(buf.String() + "written:by hand\n"))
+	require.Nil(t, err)
+	require.Equal(t, []Entry{
+		{Name: "link:docs", Value: "https://example.com"},
+		{Name: "empty", Value: ""},
+		{Name: "written", Value: "by hand"},
+	}, read)
+}
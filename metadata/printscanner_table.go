@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"bufio"
+	"fmt"
+	io "io"
+	"strings"
+	"unicode/utf8"
+)
+
+// The headings of the columns of a table.
+const (
+	tableName   = "NAME"
+	tableValue  = "VALUE"
+	tableAuthor = "AUTHOR"
+)
+
+// tablePadding is the space between the columns of a table.
+const tablePadding = "  "
+
+// A TablePrintScanner prints entries as a table aligned in columns under a
+// heading, with values in full. It scans tables back by the position of the
+// headings.
+type TablePrintScanner struct {
+}
+
+func NewTablePrintScanner() PrintScanner {
+	return &TablePrintScanner{}
+}
+
+func (p *TablePrintScanner) Print(writer io.Writer, entries []*Entry) error {
+	nameWidth, valueWidth := utf8.RuneCountInString(tableName), utf8.RuneCountInString(tableValue)
+	for _, entry := range entries {
+		if l := utf8.RuneCountInString(entry.Name); l > nameWidth {
+			nameWidth = l
+		}
+		if l := utf8.RuneCountInString(entry.Value); l > valueWidth {
+			valueWidth = l
+		}
+	}
+
+	row := func(name, value, author string) error {
+		line := p.pad(name, nameWidth) + tablePadding + p.pad(value, valueWidth) + tablePadding + author
+		_, err := fmt.Fprintln(writer, strings.TrimRight(line, " "))
+		return err
+	}
+
+	if err := row(tableName, tableValue, tableAuthor); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := row(entry.Name, entry.Value, entry.AuthorName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *TablePrintScanner) Scan(reader io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+	valueStart, authorStart := -1, -1
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := []rune(scanner.Text())
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+
+		if valueStart < 0 {
+			var err error
+			if valueStart, authorStart, err = p.scanHeading(string(line)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Name:  p.column(line, 0, valueStart),
+			Value: p.column(line, valueStart, authorStart),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// scanHeading finds where the value and author columns start.
+func (p *TablePrintScanner) scanHeading(heading string) (int, int, error) {
+	value, author := strings.Index(heading, tableValue), strings.Index(heading, tableAuthor)
+	if !strings.HasPrefix(heading, tableName) || value < 0 || author < value {
+		return 0, 0, fmt.Errorf("expected a table headed %s, %s and %s", tableName, tableValue, tableAuthor)
+	}
+	return utf8.RuneCountInString(heading[:value]), utf8.RuneCountInString(heading[:author]), nil
+}
+
+func (p *TablePrintScanner) column(line []rune, start, end int) string {
+	if start > len(line) {
+		return ""
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	return strings.TrimSpace(string(line[start:end]))
+}
+
+func (p *TablePrintScanner) pad(str string, width int) string {
+	return str + strings.Repeat(" ", width-utf8.RuneCountInString(str))
+}
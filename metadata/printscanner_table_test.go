@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ATablePrintScannerAlignsColumns(t *testing.T) {
+	entries := []*Entry{
+		{Name: "owner", Value: "alice", Author: "Jane Doe <jane@example.com>"},
+		{Name: "summary", Value: "Pays with a saved card: " + strings.Repeat("x", lineLength)},
+	}
+
+	printScanner := NewTablePrintScanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printScanner.Print(buf, entries))
+
+	valueWidth := len(entries[1].Value)
+	require.Equal(t,
+		"NAME     VALUE"+strings.Repeat(" ", valueWidth-5)+"  AUTHOR\n"+
+			"owner    alice"+strings.Repeat(" ", valueWidth-5)+"  Jane Doe\n"+
+			"summary  "+entries[1].Value+"\n",
+		buf.String(),
+	)
+
+	read, err := printScanner.Scan(buf)
+	require.Nil(t, err)
+	require.Equal(t, []Entry{
+		{Name: "owner", Value: "alice"},
+		{Name: "summary", Value: entries[1].Value},
+	}, read)
+}
+
+func Test_ATablePrintScannerNeedsAHeading(t *testing.T) {
+	_, err := NewTablePrintScanner().Scan(strings.NewReader("owner  alice\n"))
+	require.EqualError(t, err, "expected a table headed NAME, VALUE and AUTHOR")
+}
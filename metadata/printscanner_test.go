@@ -0,0 +1,48 @@
+package metadata
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPrintScannerReturnsAPrintScannerForEachFormat(t *testing.T) {
+	for _, format := range Formats {
+		printScanner, err := NewPrintScanner(format)
+		assert.Nil(t, err, format)
+		assert.NotNil(t, printScanner, format)
+	}
+
+	_, err := NewPrintScanner("xml")
+	assert.EqualError(t, err, `unknown format "xml", expected one of plain, full, table, json, yaml`)
+}
+
+func Test_PrintScannersReadBackWhatTheyPrint(t *testing.T) {
+	typed, err := NewTypedKeyValue("estimate", "5")
+	require.Nil(t, err)
+	entries := []*Entry{
+		NewKeyValue("owner", "alice"),
+		NewKeyValue("link:docs", "https://example.com: the docs"),
+		typed,
+	}
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			printScanner, err := NewPrintScanner(format)
+			require.Nil(t, err)
+
+			buf := &bytes.Buffer{}
+			require.Nil(t, printScanner.Print(buf, entries))
+
+			read, err := printScanner.Scan(buf)
+			require.Nil(t, err)
+			require.Len(t, read, len(entries))
+			for i, entry := range entries {
+				assert.Equal(t, entry.Name, read[i].Name)
+				assert.Equal(t, entry.Value, read[i].Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	io "io"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// yamlFields print each field of an entry, in the order they are printed.
+// Strings are written as double-quoted scalars and typed values as JSON,
+// which YAML reads as flow collections and scalars.
+var yamlFields = []struct {
+	name  string
+	print func(*Entry) (string, bool)
+}{
+	{
+		name:  "name",
+		print: func(e *Entry) (string, bool) { return strconv.Quote(e.Name), true },
+	},
+	{
+		name:  "value",
+		print: func(e *Entry) (string, bool) { return strconv.Quote(e.Value), !e.Typed() },
+	},
+	{
+		name:  "data",
+		print: func(e *Entry) (string, bool) { return string(e.Data), e.Typed() },
+	},
+	{
+		name:  "deleted",
+		print: func(e *Entry) (string, bool) { return "true", e.Deleted },
+	},
+	{
+		name:  "author",
+		print: func(e *Entry) (string, bool) { return strconv.Quote(e.Author), e.Author != "" },
+	},
+	{
+		name:  "commit",
+		print: func(e *Entry) (string, bool) { return strconv.Quote(e.Commit), e.Commit != "" },
+	},
+	{
+		name: "created",
+		print: func(e *Entry) (string, bool) {
+			return e.CreatedAt.Format(time.RFC3339Nano), !e.CreatedAt.IsZero()
+		},
+	},
+}
+
+// A YAMLEntry is an entry as it is laid out in YAML.
+type YAMLEntry struct {
+	Name    string      `yaml:"name"`
+	Value   string      `yaml:"value"`
+	Data    interface{} `yaml:"data"`
+	Deleted bool        `yaml:"deleted"`
+	Author  string      `yaml:"author"`
+	Commit  string      `yaml:"commit"`
+	Created string      `yaml:"created"`
+}
+
+// Entry returns the entry, with the value of a typed entry taken from its
+// data.
+func (y YAMLEntry) Entry() (Entry, error) {
+	entry := Entry{Name: y.Name, Value: y.Value, Deleted: y.Deleted, Author: y.Author, Commit: y.Commit}
+
+	if y.Created != "" {
+		created, err := time.Parse(time.RFC3339Nano, y.Created)
+		if err != nil {
+			return entry, fmt.Errorf("metadata %s: %s", y.Name, err)
+		}
+		entry.CreatedAt = created
+	}
+
+	if y.Data != nil {
+		data, err := json.Marshal(jsonValue(y.Data))
+		if err != nil {
+			return entry, fmt.Errorf("metadata %s: %s", y.Name, err)
+		}
+		entry.Data = json.RawMessage(data)
+		entry.Value = string(data)
+	}
+
+	return entry, nil
+}
+
+// jsonValue turns the mappings YAML decodes, which can have keys of any
+// type, into ones JSON can encode.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, item := range v {
+			object[fmt.Sprint(key)] = jsonValue(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+	}
+	return value
+}
+
+// A YAMLPrintScanner prints entries as a YAML sequence of mappings, and scans
+// any YAML with that layout back.
+type YAMLPrintScanner struct {
+}
+
+func NewYAMLPrintScanner() PrintScanner {
+	return &YAMLPrintScanner{}
+}
+
+func (p *YAMLPrintScanner) Print(writer io.Writer, entries []*Entry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(writer, "[]")
+		return err
+	}
+
+	for _, entry := range entries {
+		indent := "- "
+		for _, field := range yamlFields {
+			value, ok := field.print(entry)
+			if !ok {
+				continue
+			}
+			if _, err := fmt.Fprintf(writer, "%s%s: %s\n", indent, field.name, value); err != nil {
+				return err
+			}
+			indent = "  "
+		}
+	}
+
+	return nil
+}
+
+func (p *YAMLPrintScanner) Scan(reader io.Reader) ([]Entry, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned := []YAMLEntry{}
+	if err := yaml.UnmarshalStrict(content, &scanned); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(scanned))
+	for i, entry := range scanned {
+		if entries[i], err = entry.Entry(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AYAMLPrintScannerCanPrint(t *testing.T) {
+	entries := []*Entry{
+		{
+			CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Name:      "summary",
+			Value:     "Says \"hi\"\nand leaves",
+			Author:    "Jane Doe <jane@example.com>",
+		},
+		{Name: "tags", Value: `["a","b"]`, Data: json.RawMessage(`["a","b"]`)},
+		{Name: "owner", Deleted: true},
+	}
+
+	printScanner := NewYAMLPrintScanner()
+	buf := &bytes.Buffer{}
+	require.Nil(t, printScanner.Print(buf, entries))
+	require.Equal(t, `- name: "summary"
+  value: "Says \"hi\"\nand leaves"
+  author: "Jane Doe <jane@example.com>"
+  created: 2026-01-02T03:04:05Z
+- name: "tags"
+  data: ["a","b"]
+- name: "owner"
+  value: ""
+  deleted: true
+`, buf.String())
+
+	read, err := printScanner.Scan(buf)
+	require.Nil(t, err)
+	require.Equal(t, []Entry{*entries[0], *entries[1], *entries[2]}, read)
+}
+
+func Test_AYAMLPrintScannerScansPlainScalars(t *testing.T) {
+	read, err := NewYAMLPrintScanner().Scan(strings.NewReader("# metadata\n- name: owner\n  value: alice\n"))
+	require.Nil(t, err)
+	require.Equal(t, []Entry{{Name: "owner", Value: "alice"}}, read)
+}
+
+func Test_AYAMLPrintScannerScansOtherYAMLScalars(t *testing.T) {
+	long := strings.Repeat("a", 100*1024)
+	read, err := NewYAMLPrintScanner().Scan(strings.NewReader(`- name: 'owner'
+  value: 'it''s alice'
+- name: notes
+  value: |
+    first
+    second
+- name: summary
+  value: >
+    folded
+    line
+- name: estimate
+  data: {points: 3, tags: [a, b]}
+- name: long
+  value: ` + long + "\n"))
+	require.Nil(t, err)
+	require.Equal(t, []Entry{
+		{Name: "owner", Value: "it's alice"},
+		{Name: "notes", Value: "first\nsecond\n"},
+		{Name: "summary", Value: "folded line\n"},
+		{Name: "estimate", Value: `{"points":3,"tags":["a","b"]}`, Data: json.RawMessage(`{"points":3,"tags":["a","b"]}`)},
+		{Name: "long", Value: long},
+	}, read)
+}
+
+func Test_AYAMLPrintScannerRejectsOtherLayouts(t *testing.T) {
+	_, err := NewYAMLPrintScanner().Scan(strings.NewReader("owner: alice\n"))
+	require.Contains(t, err.Error(), "line 1: cannot unmarshal !!map")
+
+	_, err = NewYAMLPrintScanner().Scan(strings.NewReader("- name: owner\n  colour: blue\n"))
+	require.Contains(t, err.Error(), "line 2: field colour not found")
+}
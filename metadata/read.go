@@ -5,21 +5,21 @@ import (
 	"sort"
 )
 
+// ReadAll returns the latest live value of each piece of metadata, sorted by
+// name. Metadata whose latest entry is a tombstone is left out.
 func ReadAll(storer Storer, key io.Reader) ([]*Entry, error) {
 
 	// TODO! Merge on constraints. The current implemenation
 	// cares about unique names only.
-	var outputs []*Entry
-
-	if err := storer.ReadAllMetadata(key, &outputs); err != nil {
+	outputs, err := ReadHistory(storer, key)
+	if err != nil {
 		return nil, err
 	}
 
 	entryMap := make(map[string]*Entry)
 
-	// Outputs are returned in chronological order,
-	// so we can step through them an take the most
-	// recent as canon.
+	// Outputs are in chronological order, so we can step
+	// through them an take the most recent as canon.
 	for _, output := range outputs {
 		entryMap[output.Name] = output
 	}
@@ -27,7 +27,9 @@ func ReadAll(storer Storer, key io.Reader) ([]*Entry, error) {
 	//nolint:prealloc
 	var final []*Entry
 	for _, e := range entryMap {
-		final = append(final, e)
+		if !e.Deleted {
+			final = append(final, e)
+		}
 	}
 
 	sort.Slice(final, func(i, j int) bool {
@@ -36,3 +38,56 @@ func ReadAll(storer Storer, key io.Reader) ([]*Entry, error) {
 
 	return final, nil
 }
+
+// ReadHistory returns every entry, including superseded values and
+// tombstones, in chronological order. Discussion entries are left out.
+func ReadHistory(storer Storer, key io.Reader) ([]*Entry, error) {
+	entries, err := readEntries(storer, key)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:prealloc
+	var outputs []*Entry
+	for _, entry := range entries {
+		if entry.Name != DiscussionName {
+			outputs = append(outputs, entry)
+		}
+	}
+
+	return outputs, nil
+}
+
+// ReadDiscussion returns the entries of the discussions about an object, in
+// chronological order.
+func ReadDiscussion(storer Storer, key io.Reader) ([]*Entry, error) {
+	entries, err := readEntries(storer, key)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []*Entry{}
+	for _, entry := range entries {
+		if entry.Name == DiscussionName {
+			outputs = append(outputs, entry)
+		}
+	}
+
+	return outputs, nil
+}
+
+func readEntries(storer Storer, key io.Reader) ([]*Entry, error) {
+	var outputs []*Entry
+
+	if err := storer.ReadAllMetadata(key, &outputs); err != nil {
+		return nil, err
+	}
+
+	// Entries are stored in the order they were added, but merging
+	// notes from another repository can interleave them.
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].CreatedAt.Before(outputs[j].CreatedAt)
+	})
+
+	return outputs, nil
+}
@@ -3,6 +3,7 @@ package metadata
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/endiangroup/snaptest"
 	"github.com/stretchr/testify/mock"
@@ -46,3 +47,58 @@ func Test_CanRead(t *testing.T) {
 		snaptest.Snapshot(t, entries)
 	})
 }
+
+func Test_ReadAllLeavesOutRemovedMetadata(t *testing.T) {
+	key := bytes.NewBuffer([]byte{})
+	at := func(minute int) time.Time {
+		return time.Date(2019, 1, 1, 0, minute, 0, 0, time.UTC)
+	}
+
+	stored := []*Entry{
+		{CreatedAt: at(4), Name: "A", Value: "2"},
+		{CreatedAt: at(1), Name: "A", Value: "1"},
+		{CreatedAt: at(2), Name: "B", Value: "1"},
+		{CreatedAt: at(3), Name: "B", Deleted: true},
+	}
+
+	mockStore := &MockStorer{}
+	mockStore.On("ReadAllMetadata", key, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(1).(*[]*Entry) = append([]*Entry{}, stored...)
+		}).
+		Return(nil)
+
+	entries, err := ReadAll(mockStore, key)
+	require.Nil(t, err)
+	require.Equal(t, []*Entry{stored[0]}, entries)
+
+	history, err := ReadHistory(mockStore, key)
+	require.Nil(t, err)
+	require.Equal(t, []*Entry{stored[1], stored[2], stored[3], stored[0]}, history)
+}
+
+func Test_DiscussionEntriesAreReadSeparately(t *testing.T) {
+	key := bytes.NewBuffer([]byte{})
+	at := func(minute int) time.Time {
+		return time.Date(2019, 1, 1, 0, minute, 0, 0, time.UTC)
+	}
+
+	second := &Entry{CreatedAt: at(3), Name: DiscussionName, Value: "Second"}
+	value := &Entry{CreatedAt: at(1), Name: "A", Value: "1"}
+	first := &Entry{CreatedAt: at(2), Name: DiscussionName, Value: "First"}
+
+	mockStore := &MockStorer{}
+	mockStore.On("ReadAllMetadata", key, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(1).(*[]*Entry) = []*Entry{second, value, first}
+		}).
+		Return(nil)
+
+	history, err := ReadHistory(mockStore, key)
+	require.Nil(t, err)
+	require.Equal(t, []*Entry{value}, history)
+
+	discussion, err := ReadDiscussion(mockStore, key)
+	require.Nil(t, err)
+	require.Equal(t, []*Entry{first, second}, discussion)
+}
@@ -0,0 +1,35 @@
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Remove adds the tombstones for pieces of metadata, so that they no longer
+// appear in ReadAll. It fails if any of them has no value.
+func Remove(storer Storer, key io.Reader, tombstones ...*Entry) error {
+	content, err := ioutil.ReadAll(key)
+	if err != nil {
+		return err
+	}
+
+	entries, err := ReadAll(storer, bytes.NewBuffer(content))
+	if err != nil {
+		return err
+	}
+
+	live := map[string]bool{}
+	for _, entry := range entries {
+		live[entry.Name] = true
+	}
+
+	for _, tombstone := range tombstones {
+		if !live[tombstone.Name] {
+			return fmt.Errorf("no metadata named %s", tombstone.Name)
+		}
+	}
+
+	return Add(storer, bytes.NewBuffer(content), tombstones...)
+}
@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RemoveAddsTombstones(t *testing.T) {
+	mockStorer := &MockStorer{}
+	mockStorer.On("ReadAllMetadata", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(1).(*[]*Entry) = []*Entry{NewKeyValue("A", "1")}
+		}).
+		Return(nil)
+	mockStorer.On("StoreMetadata", bytes.NewBufferString("key"), mock.MatchedBy(func(e *Entry) bool {
+		return e.Name == "A" && e.Deleted
+	})).Return(nil)
+
+	require.Nil(t, Remove(mockStorer, bytes.NewBufferString("key"), NewTombstone("A")))
+	mockStorer.AssertExpectations(t)
+
+	require.Equal(t,
+		fmt.Errorf("no metadata named B"),
+		Remove(mockStorer, bytes.NewBufferString("key"), NewTombstone("B")),
+	)
+}
@@ -0,0 +1,227 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/endiangroup/specstack/errors"
+)
+
+// SchemaFileName is the name of the schema file, at the top of the project.
+const SchemaFileName = ".specschema.json"
+
+// The kinds of object metadata can be attached to.
+const (
+	KindStory    = "story"
+	KindScenario = "scenario"
+	KindStep     = "step"
+	KindActor    = "actor"
+)
+
+// The types a key's values can be given.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeDate    = "date"
+	TypeList    = "list"
+	TypeObject  = "object"
+)
+
+/*
+A Schema defines the metadata a project allows. It is read from a JSON file
+like:
+
+	{
+		"keys": {
+			"status": {
+				"type": "string",
+				"enum": ["todo", "doing", "done"],
+				"appliesTo": ["story", "scenario"],
+				"required": true
+			},
+			"estimate": {"type": "number", "appliesTo": ["scenario"]}
+		}
+	}
+
+Keys that aren't in the schema are not allowed. A key without a type takes any
+value, and a key without appliesTo can be set on any kind of object. Required
+keys must be set on every object of the kinds they apply to.
+*/
+type Schema struct {
+	Keys map[string]*KeySchema
+}
+
+// A KeySchema defines the values allowed for a key.
+type KeySchema struct {
+	Type      string
+	Enum      []string
+	AppliesTo []string
+	Required  bool
+}
+
+// ReadSchema reads a schema and checks that it is well formed.
+func ReadSchema(reader io.Reader) (*Schema, error) {
+	schema := &Schema{}
+	if err := json.NewDecoder(reader).Decode(schema); err != nil {
+		return nil, fmt.Errorf("invalid metadata schema: %s", err)
+	}
+
+	for name, key := range schema.Keys {
+		if err := key.assertWellFormed(); err != nil {
+			return nil, fmt.Errorf("invalid metadata schema for %s: %s", name, err)
+		}
+	}
+
+	return schema, nil
+}
+
+func (k *KeySchema) assertWellFormed() error {
+	switch k.Type {
+	case "", TypeString, TypeNumber, TypeBoolean, TypeDate, TypeList, TypeObject:
+	default:
+		return fmt.Errorf("unknown type %s", k.Type)
+	}
+
+	for _, kind := range k.AppliesTo {
+		switch kind {
+		case KindStory, KindScenario, KindStep, KindActor:
+		default:
+			return fmt.Errorf("unknown kind %s", kind)
+		}
+	}
+
+	return nil
+}
+
+func (k *KeySchema) appliesTo(kind string) bool {
+	if len(k.AppliesTo) == 0 {
+		return true
+	}
+	for _, applies := range k.AppliesTo {
+		if applies == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateEntry checks that an entry may be written to an object of the
+// given kind, returning an *errors.ValidationField if not.
+func (s *Schema) ValidateEntry(kind string, entry *Entry) error {
+	key, ok := s.Keys[entry.Name]
+
+	// Metadata that breaks the schema can always be removed, so that it can
+	// be cleaned up, but required metadata can't.
+	if entry.Deleted {
+		if ok && key.Required && key.appliesTo(kind) {
+			return &errors.ValidationField{Field: entry.Name, Message: "is required"}
+		}
+		return nil
+	}
+
+	if !ok {
+		return &errors.ValidationField{Field: entry.Name, Message: "is not in the metadata schema"}
+	}
+
+	if !key.appliesTo(kind) {
+		return &errors.ValidationField{Field: entry.Name, Message: fmt.Sprintf("cannot be set on a %s", kind)}
+	}
+
+	if err := key.validateType(entry); err != nil {
+		return err
+	}
+
+	return key.validateEnum(entry)
+}
+
+func (k *KeySchema) validateType(entry *Entry) error {
+	var err error
+
+	switch k.Type {
+	case TypeString:
+		var s string
+		err = entry.Decode(&s)
+	case TypeNumber:
+		_, err = entry.Number()
+	case TypeBoolean:
+		var b bool
+		err = entry.Decode(&b)
+	case TypeDate:
+		_, err = entry.Date()
+	case TypeList:
+		var l []interface{}
+		err = entry.Decode(&l)
+	case TypeObject:
+		var o map[string]interface{}
+		err = entry.Decode(&o)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("must be a %s", k.Type)
+	if k.Type != TypeString && k.Type != TypeDate && !entry.Typed() {
+		message += fmt.Sprintf(", e.g. %s:=<json>", entry.Name)
+	}
+	return &errors.ValidationField{Field: entry.Name, Message: message}
+}
+
+func (k *KeySchema) validateEnum(entry *Entry) error {
+	if len(k.Enum) == 0 {
+		return nil
+	}
+
+	value := entry.Value
+	if entry.Typed() {
+		if err := entry.Decode(&value); err != nil {
+			value = entry.Value
+		}
+	}
+
+	for _, allowed := range k.Enum {
+		if value == allowed {
+			return nil
+		}
+	}
+
+	return &errors.ValidationField{
+		Field:   entry.Name,
+		Message: fmt.Sprintf("must be one of %s", strings.Join(k.Enum, ", ")),
+	}
+}
+
+// Validate checks the current metadata of an object of the given kind: that
+// every entry is allowed, and that every required key is set. The problems
+// are returned as errors.ValidationErrors.
+func (s *Schema) Validate(kind string, entries []*Entry) error {
+	errs := errors.ValidationErrors{}
+	set := map[string]bool{}
+
+	for _, entry := range entries {
+		set[entry.Name] = true
+		if err := s.ValidateEntry(kind, entry); err != nil {
+			errs = errs.Append(err)
+		}
+	}
+
+	names := []string{}
+	for name, key := range s.Keys {
+		if key.Required && key.appliesTo(kind) && !set[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = errs.Append(&errors.ValidationField{Field: name, Message: "is required"})
+	}
+
+	if errs.Any() {
+		return errs
+	}
+	return nil
+}
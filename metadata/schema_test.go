@@ -0,0 +1,79 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/endiangroup/specstack/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"keys": {
+		"status": {
+			"type": "string",
+			"enum": ["todo", "done"],
+			"appliesTo": ["story", "scenario"],
+			"required": true
+		},
+		"estimate": {"type": "number", "appliesTo": ["scenario"]},
+		"notes": {}
+	}
+}`
+
+func readTestSchema(t *testing.T) *Schema {
+	schema, err := ReadSchema(strings.NewReader(testSchema))
+	require.Nil(t, err)
+	return schema
+}
+
+func Test_ASchemaMustBeWellFormed(t *testing.T) {
+	_, err := ReadSchema(strings.NewReader(`{"keys": {"status": {"type": "colour"}}}`))
+	require.EqualError(t, err, "invalid metadata schema for status: unknown type colour")
+
+	_, err = ReadSchema(strings.NewReader(`{"keys": {"status": {"appliesTo": ["feature"]}}}`))
+	require.EqualError(t, err, "invalid metadata schema for status: unknown kind feature")
+}
+
+func Test_ASchemaValidatesEntries(t *testing.T) {
+	schema := readTestSchema(t)
+	estimate, err := NewTypedKeyValue("estimate", "3")
+	require.Nil(t, err)
+
+	for _, test := range []struct {
+		kind  string
+		entry *Entry
+		err   error
+	}{
+		{KindStory, NewKeyValue("status", "todo"), nil},
+		{KindScenario, estimate, nil},
+		{KindStep, NewKeyValue("notes", "anything"), nil},
+		{KindStory, NewKeyValue("Status", "todo"),
+			&errors.ValidationField{Field: "Status", Message: "is not in the metadata schema"}},
+		{KindStory, NewKeyValue("status", "Done"),
+			&errors.ValidationField{Field: "status", Message: "must be one of todo, done"}},
+		{KindStep, NewKeyValue("status", "todo"),
+			&errors.ValidationField{Field: "status", Message: "cannot be set on a step"}},
+		{KindScenario, NewKeyValue("estimate", "3"),
+			&errors.ValidationField{Field: "estimate", Message: "must be a number, e.g. estimate:=<json>"}},
+		{KindStory, NewTombstone("status"),
+			&errors.ValidationField{Field: "status", Message: "is required"}},
+		{KindStory, NewTombstone("state"), nil},
+	} {
+		require.Equal(t, test.err, schema.ValidateEntry(test.kind, test.entry))
+	}
+}
+
+func Test_ASchemaValidatesTheMetadataOfAnObject(t *testing.T) {
+	schema := readTestSchema(t)
+
+	require.Nil(t, schema.Validate(KindActor, []*Entry{}))
+	require.Nil(t, schema.Validate(KindStory, []*Entry{NewKeyValue("status", "done")}))
+	require.Equal(t,
+		errors.ValidationErrors{
+			&errors.ValidationField{Field: "state", Message: "is not in the metadata schema"},
+			&errors.ValidationField{Field: "status", Message: "is required"},
+		},
+		schema.Validate(KindScenario, []*Entry{NewKeyValue("state", "done")}),
+	)
+}
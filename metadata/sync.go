@@ -1,13 +1,19 @@
 package metadata
 
+import (
+	"context"
+
+	"github.com/endiangroup/specstack/repository"
+)
+
 func PrepareSync(sp SyncPreparer) error {
 	return sp.PrepareMetadataSync()
 }
 
-func Pull(puller Puller, from string) error {
+func Pull(puller Puller, from string) (*repository.MetadataMerge, error) {
 	return puller.PullMetadata(from)
 }
 
-func Push(pusher Pusher, to string) error {
-	return pusher.PushMetadata(to)
+func Push(ctx context.Context, pusher Pusher, to string) error {
+	return pusher.PushMetadata(ctx, to)
 }
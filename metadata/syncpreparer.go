@@ -1,12 +1,18 @@
 package metadata
 
+import (
+	"context"
+
+	"github.com/endiangroup/specstack/repository"
+)
+
 type SyncPreparer interface {
 	PrepareMetadataSync() error
 }
 
 type Puller interface {
-	PullMetadata(from string) error
+	PullMetadata(from string) (*repository.MetadataMerge, error)
 }
 type Pusher interface {
-	PushMetadata(to string) error
+	PushMetadata(ctx context.Context, to string) error
 }
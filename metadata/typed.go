@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayouts are the layouts a date value can be written in.
+var dateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// NewTypedKeyValue creates an entry whose value is a JSON number, boolean,
+// string, list or object. The value is kept in Data as compact JSON, and also
+// in Value so that it prints like any other.
+func NewTypedKeyValue(key, value string) (*Entry, error) {
+	data := &bytes.Buffer{}
+	if err := json.Compact(data, []byte(value)); err != nil {
+		return nil, fmt.Errorf("invalid JSON value for %s: %s", key, err)
+	}
+
+	e := NewKeyValue(key, data.String())
+	e.Data = json.RawMessage(data.Bytes())
+	return e, nil
+}
+
+// Typed reports whether the entry was given a JSON value.
+func (e *Entry) Typed() bool {
+	return len(e.Data) > 0
+}
+
+// Decode unmarshals the entry's value into v, in the same way as
+// json.Unmarshal. An untyped value decodes as a JSON string.
+func (e *Entry) Decode(v interface{}) error {
+	data := []byte(e.Data)
+	if !e.Typed() {
+		var err error
+		if data, err = json.Marshal(e.Value); err != nil {
+			return err
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("metadata %s: %s", e.Name, err)
+	}
+	return nil
+}
+
+// Number returns the entry's value as a number.
+func (e *Entry) Number() (float64, error) {
+	var n float64
+	err := e.Decode(&n)
+	return n, err
+}
+
+// Date returns the entry's value as a date, written either as an RFC 3339
+// timestamp or as YYYY-MM-DD.
+func (e *Entry) Date() (time.Time, error) {
+	var s string
+	if err := e.Decode(&s); err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("metadata %s: %q is not a date", e.Name, s)
+}
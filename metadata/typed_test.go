@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ATypedEntryKeepsItsJSONValue(t *testing.T) {
+	entry, err := NewTypedKeyValue("tags", `[ "a", "b" ]`)
+	require.Nil(t, err)
+	require.True(t, entry.Typed())
+	require.Equal(t, `["a","b"]`, entry.Value)
+
+	tags := []string{}
+	require.Nil(t, entry.Decode(&tags))
+	require.Equal(t, []string{"a", "b"}, tags)
+
+	_, err = NewTypedKeyValue("tags", `[a, b]`)
+	require.NotNil(t, err)
+}
+
+func Test_ATypedEntryCanBeReadAsANumber(t *testing.T) {
+	entry, err := NewTypedKeyValue("estimate", "3.5")
+	require.Nil(t, err)
+
+	n, err := entry.Number()
+	require.Nil(t, err)
+	require.Equal(t, 3.5, n)
+
+	_, err = NewKeyValue("estimate", "3.5").Number()
+	require.NotNil(t, err)
+}
+
+func Test_AnEntryCanBeReadAsADate(t *testing.T) {
+	entry, err := NewTypedKeyValue("due", `"2018-06-01"`)
+	require.Nil(t, err)
+
+	date, err := entry.Date()
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = NewKeyValue("due", "2018-06-01T12:00:00Z").Date()
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC), date)
+
+	_, err = NewKeyValue("due", "soon").Date()
+	require.Equal(t, `metadata due: "soon" is not a date`, err.Error())
+}
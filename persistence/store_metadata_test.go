@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"testing"
 
+	"github.com/endiangroup/specstack/metadata"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -60,3 +63,25 @@ func Test_StoreMetadata_CanReadAllMetadataFromASource(t *testing.T) {
 		require.Equal(t, []MyObject{object}, objects)
 	})
 }
+
+func Test_StoreMetadata_TypedMetadataRoundTrips(t *testing.T) {
+	mockConfigStorer := &MockConfigStorer{}
+	mockMetadataStorer := &MockMetadataStorer{}
+	rs := NewStore(mockConfigStorer, mockMetadataStorer)
+
+	entry, err := metadata.NewTypedKeyValue("estimate", `{"points": 3, "tags": ["a", "b"]}`)
+	require.Nil(t, err)
+
+	key := bytes.NewBufferString(t.Name())
+	var stored []byte
+	mockMetadataStorer.On("SetMetadata", key, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]byte) }).
+		Return(nil)
+	require.Nil(t, rs.StoreMetadata(key, entry))
+
+	mockMetadataStorer.On("GetMetadata", key).Return(func(io.Reader) [][]byte { return [][]byte{stored} }, nil)
+	entries := []*metadata.Entry{}
+	require.Nil(t, rs.ReadAllMetadata(key, &entries))
+
+	require.Equal(t, []*metadata.Entry{entry}, entries)
+}
@@ -1,14 +1,24 @@
 package personas
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/endiangroup/specstack"
 	"github.com/endiangroup/specstack/config"
+	"github.com/endiangroup/specstack/discussion"
 	"github.com/endiangroup/specstack/errors"
+	"github.com/endiangroup/specstack/fuzzy"
 	"github.com/endiangroup/specstack/metadata"
 	"github.com/endiangroup/specstack/persistence"
+	"github.com/endiangroup/specstack/progress"
 	"github.com/endiangroup/specstack/repository"
 	"github.com/endiangroup/specstack/snapshot"
 	"github.com/endiangroup/specstack/specification"
@@ -22,12 +32,13 @@ func (err MissingRequiredConfigValueErr) Error() string {
 }
 
 type Developer struct {
-	path   string
-	store  *persistence.Store
-	config *config.Config
-	repo   repository.Repository
-	stdout io.Writer
-	stderr io.Writer
+	path     string
+	store    *persistence.Store
+	config   *config.Config
+	repo     repository.Repository
+	revision string
+	stdout   io.Writer
+	stderr   io.Writer
 }
 
 func NewDeveloper(
@@ -128,25 +139,73 @@ func (d *Developer) SetConfiguration(name, value string) error {
 	return err
 }
 
+// SelectRevision makes the developer read the specification as it was at a
+// commit, tag or branch, instead of from the working tree.
+func (d *Developer) SelectRevision(revision string) error {
+	hash, err := d.repo.ResolveRevision(revision)
+	if err != nil {
+		return err
+	}
+
+	d.revision = hash
+	return nil
+}
+
+func (d *Developer) assertWorkingTree() error {
+	if d.revision != "" {
+		return fmt.Errorf("metadata can only be added to the working tree, not to a past revision")
+	}
+	return nil
+}
+
 func (d *Developer) specificationFactory() *specification.Factory {
-	return specification.NewFactory(
+	roots := d.featureRoots()
+	featuresDir := d.config.Project.FeaturesDir
+	if len(roots) > 0 {
+		featuresDir = roots[0].Path
+	}
+
+	factory := specification.NewFactory(
 		afero.NewOsFs(),
-		d.config.Project.FeaturesDir,
+		featuresDir,
+		d.config.Project.Dialect,
 		d.stderr,
 	)
+
+	factory.Roots = roots
+	factory.Include = d.config.Project.IncludePatterns()
+	factory.Exclude = d.config.Project.ExcludePatterns()
+
+	if d.revision != "" {
+		factory.Revision = d.revision
+		factory.RevisionSourcer = d.repo
+	}
+
+	return factory
+}
+
+func (d *Developer) featureRoots() []specification.Root {
+	roots := []specification.Root{}
+	for _, root := range d.config.Project.FeatureRoots() {
+		roots = append(roots, specification.Root{Label: root.Label, Path: root.Path})
+	}
+	return roots
 }
 
 func (d *Developer) specification() (*specification.Specification, specification.Reader, error) {
 	return d.specificationFactory().Specification()
 }
 
-func (d *Developer) findStoryObject(name string) (*specification.Story, io.Reader, error) {
+func (d *Developer) findStoryObject(
+	name string,
+	filters ...specification.QueryMapFunc,
+) (*specification.Story, io.Reader, error) {
 	spec, reader, err := d.specification()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	story, err := spec.FindStory(name)
+	story, err := spec.FindStory(name, filters...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -159,12 +218,15 @@ func (d *Developer) findStoryObject(name string) (*specification.Story, io.Reade
 	return story, object, nil
 }
 
-func (d *Developer) findScenarioObject(name, story string) (*specification.Scenario, io.Reader, error) {
+func (d *Developer) findScenarioObject(
+	name, story string,
+	filters ...specification.QueryMapFunc,
+) (*specification.Scenario, io.Reader, error) {
 	spec, reader, err := d.specification()
 	if err != nil {
 		return nil, nil, err
 	}
-	scenario, err := spec.FindScenario(name, story)
+	scenario, err := spec.FindScenario(name, story, filters...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -177,51 +239,310 @@ func (d *Developer) findScenarioObject(name, story string) (*specification.Scena
 	return scenario, object, nil
 }
 
-func (d *Developer) AddMetadataToStory(storyName, key, value string) error {
-	_, object, err := d.findStoryObject(storyName)
+func (d *Developer) findStepObject(
+	step, scenario, story string,
+	filters ...specification.QueryMapFunc,
+) (*specification.Step, io.Reader, error) {
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, nil, err
+	}
+	foundScenario, err := spec.FindScenario(scenario, story, filters...)
+	if err != nil {
+		return nil, nil, err
+	}
+	foundStep, err := foundScenario.FindStep(step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := reader.ReadSource(foundStep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return foundStep, object, nil
+}
+
+func (d *Developer) GetStoryMetadata(name string, filters ...specification.QueryMapFunc) ([]*metadata.Entry, error) {
+	_, object, err := d.findStoryObject(name, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.ReadAll(d.store, object)
+}
+
+func (d *Developer) GetScenarioMetadata(
+	name, story string,
+	filters ...specification.QueryMapFunc,
+) ([]*metadata.Entry, error) {
+	_, object, err := d.findScenarioObject(name, story, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.ReadAll(d.store, object)
+}
+
+func (d *Developer) GetStepMetadata(
+	step, scenario, story string,
+	filters ...specification.QueryMapFunc,
+) ([]*metadata.Entry, error) {
+	_, object, err := d.findStepObject(step, scenario, story, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.ReadAll(d.store, object)
+}
+
+// stamp records the user as the author of the entries, along with the commit
+// they were written against. In a repository without commits the commit is
+// left empty.
+func (d *Developer) stamp(entries ...*metadata.Entry) []*metadata.Entry {
+	commit, _ := d.repo.ResolveRevision("HEAD")
+
+	for _, entry := range entries {
+		entry.Author = d.config.User.String()
+		entry.Commit = commit
+	}
+
+	return entries
+}
+
+// metadataObject finds the object a target names, and its kind.
+func (d *Developer) metadataObject(target specstack.MetadataTarget) (string, io.Reader, error) {
+	var (
+		object io.Reader
+		err    error
+	)
+
+	switch {
+	case target.Actor != "":
+		_, object, err = d.findActorObject(target.Actor)
+		return metadata.KindActor, object, err
+	case target.Step != "":
+		_, object, err = d.findStepObject(target.Step, target.Scenario, target.Story, target.Filters...)
+		return metadata.KindStep, object, err
+	case target.Scenario != "":
+		_, object, err = d.findScenarioObject(target.Scenario, target.Story, target.Filters...)
+		return metadata.KindScenario, object, err
+	case target.Story != "":
+		_, object, err = d.findStoryObject(target.Story, target.Filters...)
+		return metadata.KindStory, object, err
+	}
+
+	return "", nil, fmt.Errorf("specify a story, scenario or actor")
+}
+
+// metadataSchema reads the project's metadata schema, which like the features
+// directory is relative to the working directory. Without a schema file, any
+// metadata is allowed and the schema is nil.
+func (d *Developer) metadataSchema() (*metadata.Schema, error) {
+	file, err := afero.NewOsFs().Open(metadata.SchemaFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return metadata.ReadSchema(file)
+}
+
+// validateMetadata checks entries against the schema, if there is one. The
+// progress of a scenario can't be written as metadata, as it only moves
+// through the project's workflow.
+func (d *Developer) validateMetadata(kind string, entries ...*metadata.Entry) error {
+	errs := errors.ValidationErrors{}
+	others := []*metadata.Entry{}
+	for _, entry := range entries {
+		if entry.Name == progress.MetadataName {
+			errs = errs.Append(&errors.ValidationField{
+				Field:   entry.Name,
+				Message: "can only be changed with spec progress set",
+			})
+			continue
+		}
+		others = append(others, entry)
+	}
+
+	schemaErrs, err := d.schemaErrors(kind, others...)
 	if err != nil {
 		return err
 	}
 
-	if err := metadata.Add(d.store, object, metadata.NewKeyValue(key, value)); err != nil {
+	if errs = append(errs, schemaErrs...); errs.Any() {
+		return errs
+	}
+	return nil
+}
+
+// schemaErrors checks entries against the schema, if there is one.
+func (d *Developer) schemaErrors(kind string, entries ...*metadata.Entry) (errors.ValidationErrors, error) {
+	schema, err := d.metadataSchema()
+	if err != nil || schema == nil {
+		return nil, err
+	}
+
+	errs := errors.ValidationErrors{}
+	for _, entry := range entries {
+		if err := schema.ValidateEntry(kind, entry); err != nil {
+			errs = errs.Append(err)
+		}
+	}
+	return errs, nil
+}
+
+// addMetadata checks entries against the schema, then records them on an
+// object of the given kind.
+func (d *Developer) addMetadata(kind string, object io.Reader, entries ...*metadata.Entry) error {
+	if err := d.validateMetadata(kind, entries...); err != nil {
 		return err
 	}
 
-	if d.config.Project.PushingMode == config.ModeAuto {
-		return errors.WarningOrNil(d.Push())
+	if err := metadata.Add(d.store, object, d.stamp(entries...)...); err != nil {
+		return err
 	}
 
-	return nil
+	return d.autoPush(entries...)
+}
+
+// autoPush pushes metadata when the pushing mode is automatic. When it can't,
+// the change is queued in the outbox and a warning is given instead.
+func (d *Developer) autoPush(entries ...*metadata.Entry) error {
+	if d.config.Project.PushingMode != config.ModeAuto {
+		return nil
+	}
+
+	err := d.Push()
+	if err == nil {
+		return nil
+	}
+
+	// The change has been made by now, so not being able to queue it is
+	// only worth a warning too.
+	if outboxErr := d.queuePush(err, entries...); outboxErr != nil {
+		return errors.WarningOrNil(fmt.Errorf("the change could not be pushed, or queued to be pushed later: %s", outboxErr))
+	}
+
+	return errors.WarningOrNil(fmt.Errorf("the change was queued to be pushed later: %s", err))
+}
+
+func (d *Developer) queuePush(reason error, entries ...*metadata.Entry) error {
+	outbox, err := d.outbox()
+	if err != nil {
+		return err
+	}
+	return outbox.Queue(metadata.NewPendingPush(reason, entries...))
+}
+
+func (d *Developer) outbox() (*metadata.Outbox, error) {
+	path, err := d.repo.StatePath("outbox")
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewOutbox(path), nil
 }
 
-func (d *Developer) AddMetadataToScenario(name, storyName, key, value string) error {
-	_, object, err := d.findScenarioObject(name, storyName)
+// PendingPushes returns the metadata changes waiting to be pushed.
+func (d *Developer) PendingPushes() ([]metadata.PendingPush, error) {
+	outbox, err := d.outbox()
 	if err != nil {
+		return nil, err
+	}
+	return outbox.Pending()
+}
+
+// RetryPendingPushes pushes metadata if any changes are waiting to be pushed,
+// giving up when the context is done. It returns how many changes were
+// waiting.
+func (d *Developer) RetryPendingPushes(ctx context.Context) (int, error) {
+	pending, err := d.PendingPushes()
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+
+	if err := d.push(ctx); err != nil {
+		return len(pending), err
+	}
+	return len(pending), nil
+}
+
+// SetMetadata records new values for metadata, superseding any earlier ones.
+func (d *Developer) SetMetadata(target specstack.MetadataTarget, entries ...*metadata.Entry) error {
+	if err := d.assertWorkingTree(); err != nil {
 		return err
 	}
 
-	if err := metadata.Add(d.store, object, metadata.NewKeyValue(key, value)); err != nil {
+	kind, object, err := d.metadataObject(target)
+	if err != nil {
 		return err
 	}
 
-	if d.config.Project.PushingMode == config.ModeAuto {
-		return errors.WarningOrNil(d.Push())
+	return d.addMetadata(kind, object, entries...)
+}
+
+// RemoveMetadata records tombstones for metadata, hiding it from the current
+// view while keeping it in the history.
+func (d *Developer) RemoveMetadata(target specstack.MetadataTarget, keys ...string) error {
+	if err := d.assertWorkingTree(); err != nil {
+		return err
 	}
 
-	return nil
+	kind, object, err := d.metadataObject(target)
+	if err != nil {
+		return err
+	}
+
+	tombstones := []*metadata.Entry{}
+	for _, key := range keys {
+		tombstones = append(tombstones, metadata.NewTombstone(key))
+	}
+
+	if err := d.validateMetadata(kind, tombstones...); err != nil {
+		return err
+	}
+
+	if err := metadata.Remove(d.store, object, d.stamp(tombstones...)...); err != nil {
+		return err
+	}
+
+	return d.autoPush(tombstones...)
 }
 
-func (d *Developer) GetStoryMetadata(name string) ([]*metadata.Entry, error) {
-	_, object, err := d.findStoryObject(name)
+func (d *Developer) GetMetadataHistory(target specstack.MetadataTarget) ([]*metadata.Entry, error) {
+	_, object, err := d.metadataObject(target)
 	if err != nil {
 		return nil, err
 	}
 
-	return metadata.ReadAll(d.store, object)
+	return metadata.ReadHistory(d.store, object)
+}
+
+func (d *Developer) findActorObject(name string) (*specification.Actor, io.Reader, error) {
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actor, err := spec.FindActor(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := reader.ReadSource(actor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return actor, object, nil
 }
 
-func (d *Developer) GetScenarioMetadata(name, story string) ([]*metadata.Entry, error) {
-	_, object, err := d.findScenarioObject(name, story)
+func (d *Developer) GetActorMetadata(name string) ([]*metadata.Entry, error) {
+	_, object, err := d.findActorObject(name)
 	if err != nil {
 		return nil, err
 	}
@@ -229,27 +550,946 @@ func (d *Developer) GetScenarioMetadata(name, story string) ([]*metadata.Entry,
 	return metadata.ReadAll(d.store, object)
 }
 
-func (d *Developer) Pull() error {
-	if d.config.Project.Remote == "" {
-		return fmt.Errorf("configure a project remote first")
+func (d *Developer) ListActors() ([]*specification.Actor, error) {
+	spec, _, err := d.specification()
+	if err != nil {
+		return nil, err
 	}
-	return metadata.Pull(d.repo, d.config.Project.Remote)
+
+	return spec.Actors(), nil
 }
 
-func (d *Developer) Push() error {
-	if d.config.Project.Remote == "" {
-		return fmt.Errorf("configure a project remote first")
+// AssignScenarioIDs tags every scenario that has no ID with a new one, and
+// moves any metadata it has to the new ID.
+func (d *Developer) AssignScenarioIDs() ([]specification.IDAssignment, error) {
+	if err := d.assertWorkingTree(); err != nil {
+		return nil, err
+	}
+
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := spec.NewScenarioIDs()
+	tags := map[string]map[int]string{}
+	moved := []*metadata.Entry{}
+	for _, assignment := range assignments {
+		entries, err := d.moveScenarioMetadata(reader, assignment)
+		if err != nil {
+			return nil, err
+		}
+		moved = append(moved, entries...)
+
+		file := assignment.Scenario.Story.SourceIdentifier
+		if tags[file] == nil {
+			tags[file] = map[int]string{}
+		}
+		tags[file][assignment.Scenario.LineNumber()] = specification.IDTagPrefix + string(assignment.ID)
+	}
+
+	fs := afero.NewOsFs()
+	for file, fileTags := range tags {
+		if err := insertTags(fs, file, fileTags); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(moved) == 0 {
+		return assignments, nil
 	}
-	return metadata.Push(d.repo, d.config.Project.Remote)
+	return assignments, d.autoPush(moved...)
 }
 
-func (d *Developer) TransferScenarioMetadata() error {
+// moveScenarioMetadata copies the metadata of a scenario, its examples and
+// its steps to their keys under the scenario's new ID, returning what was
+// copied.
+func (d *Developer) moveScenarioMetadata(
+	reader specification.ReadSourcer,
+	assignment specification.IDAssignment,
+) ([]*metadata.Entry, error) {
+	objects := map[specification.Sourcer]specification.Sourcer{assignment.Scenario: assignment.ID}
+	for i, example := range assignment.Scenario.ExampleScenarios() {
+		objects[example] = assignment.ID.Example(i + 1)
+	}
+	for _, step := range assignment.Scenario.ScenarioSteps() {
+		objects[step] = specification.StepSource(assignment.ID, step.Text)
+	}
+
+	moved := []*metadata.Entry{}
+	for from, to := range objects {
+		key, err := readKey(reader, from)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := d.transferableMetadata(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		toObject, err := reader.ReadSource(to)
+		if err != nil {
+			return nil, err
+		}
+		if err := metadata.Add(d.store, toObject, entries...); err != nil {
+			return nil, err
+		}
+		moved = append(moved, entries...)
+	}
+
+	return moved, nil
+}
+
+// transferableMetadata reads the current metadata of an object along with its
+// discussions, which is what moves with it to a new key.
+func (d *Developer) transferableMetadata(key []byte) ([]*metadata.Entry, error) {
+	entries, err := metadata.ReadAll(d.store, bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+	discussion, err := metadata.ReadDiscussion(d.store, bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(entries, discussion...), nil
+}
+
+func insertTags(fs afero.Fs, file string, tags map[int]string) error {
+	info, err := fs.Stat(file)
+	if err != nil {
+		return err
+	}
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, file, specification.InsertTags(content, tags), info.Mode())
+}
+
+// CheckMetadata audits the metadata of every story, scenario, step and actor
+// against the project's metadata schema.
+func (d *Developer) CheckMetadata() ([]specstack.MetadataViolation, error) {
+	schema, err := d.metadataSchema()
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("no metadata schema, add one to %s", metadata.SchemaFileName)
+	}
+
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &metadataChecker{schema: schema, store: d.store, reader: reader}
+	for _, story := range spec.Stories() {
+		checker.check(metadata.KindStory, story.Name, story.SourceIdentifier, story)
+		for _, scenario := range spec.Scenarios(story) {
+			checker.checkScenario(scenario)
+		}
+	}
+	for _, actor := range spec.Actors() {
+		checker.check(metadata.KindActor, actor.Name, actor.SourceIdentifier, actor)
+	}
+
+	return checker.violations, checker.err
+}
+
+// A metadataChecker collects the objects whose metadata breaks a schema.
+type metadataChecker struct {
+	schema     *metadata.Schema
+	store      *persistence.Store
+	reader     specification.ReadSourcer
+	violations []specstack.MetadataViolation
+	err        error
+}
+
+func (c *metadataChecker) checkScenario(scenario *specification.Scenario) {
+	location := fmt.Sprintf("%s:%d", scenario.Story.SourceIdentifier, scenario.LineNumber())
+	c.check(metadata.KindScenario, scenario.Name, location, scenario)
+
+	for i, example := range scenario.ExampleScenarios() {
+		name := fmt.Sprintf("%s%s%d", scenario.Name, specification.ExampleSeparator, i+1)
+		exampleLocation := fmt.Sprintf("%s:%d", scenario.Story.SourceIdentifier, example.LineNumber())
+		c.check(metadata.KindScenario, name, exampleLocation, example)
+	}
+
+	for _, step := range scenario.ScenarioSteps() {
+		stepLocation := fmt.Sprintf("%s:%d", scenario.Story.SourceIdentifier, step.Location.Line)
+		c.check(metadata.KindStep, step.Keyword+step.Text, stepLocation, step)
+	}
+}
+
+func (c *metadataChecker) check(kind, name, location string, object specification.Sourcer) {
+	if c.err != nil {
+		return
+	}
+
+	key, err := c.reader.ReadSource(object)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	entries, err := metadata.ReadAll(c.store, key)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	if err := c.schema.Validate(kind, entries); err != nil {
+		c.violations = append(c.violations, specstack.MetadataViolation{
+			Kind:     kind,
+			Name:     name,
+			Location: location,
+			Err:      err,
+		})
+	}
+}
+
+// ExportMetadata returns the current metadata of every story and scenario,
+// including those without any.
+func (d *Developer) ExportMetadata() ([]*specstack.MetadataRecord, error) {
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := metadataObjects(spec)
+	records := make([]*specstack.MetadataRecord, len(objects))
+	for i, object := range objects {
+		key, err := readKey(reader, object.sourcer)
+		if err != nil {
+			return nil, err
+		}
+
+		if object.record.Hash, err = d.repo.ObjectHash(bytes.NewReader(key)); err != nil {
+			return nil, err
+		}
+		if object.record.Metadata, err = metadata.ReadAll(d.store, bytes.NewReader(key)); err != nil {
+			return nil, err
+		}
+		records[i] = object.record
+	}
+
+	return records, nil
+}
+
+// ImportMetadata records the metadata of exported records on the stories and
+// scenarios they match, by ID, then name, then fuzzy match. Only values that
+// differ from the current ones are recorded, and nothing is removed. Nothing is
+// recorded when any record can't be imported, or when it's a dry run.
+func (d *Developer) ImportMetadata(
+	records []*specstack.MetadataRecord,
+	dryRun bool,
+) ([]specstack.MetadataImport, error) {
+	if err := d.assertWorkingTree(); err != nil {
+		return nil, err
+	}
+
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := metadataObjects(spec)
+	imports := make([]specstack.MetadataImport, len(records))
+	keys := make([][]byte, len(records))
+	failed := false
+	for i, record := range records {
+		imports[i], keys[i], err = d.planImport(reader, objects, record)
+		if err != nil {
+			return nil, err
+		}
+		failed = failed || imports[i].Err != nil
+	}
+
+	if dryRun || failed {
+		return imports, nil
+	}
+
+	changed := []*metadata.Entry{}
+	for i, imported := range imports {
+		if len(imported.Changed) == 0 {
+			continue
+		}
+		if err := metadata.Add(d.store, bytes.NewReader(keys[i]), d.stamp(imported.Changed...)...); err != nil {
+			return nil, err
+		}
+		changed = append(changed, imported.Changed...)
+	}
+
+	return imports, d.autoPush(changed...)
+}
+
+// planImport works out what importing a record would change, returning the
+// key of the object it matched.
+func (d *Developer) planImport(
+	reader specification.ReadSourcer,
+	objects []metadataObject,
+	record *specstack.MetadataRecord,
+) (specstack.MetadataImport, []byte, error) {
+	imported := specstack.MetadataImport{Record: record}
+
+	object, match, err := matchMetadataObject(objects, record)
+	if err != nil {
+		imported.Err = err
+		return imported, nil, nil
+	}
+	imported.Target, imported.Match = object.record, match
+
+	key, err := readKey(reader, object.sourcer)
+	if err != nil {
+		return imported, nil, err
+	}
+	current, err := metadata.ReadAll(d.store, bytes.NewReader(key))
+	if err != nil {
+		return imported, nil, err
+	}
+
+	if imported.Changed, err = changedMetadata(current, record.Metadata); err != nil {
+		imported.Err = err
+		return imported, key, nil
+	}
+	imported.Err = d.validateMetadata(record.Kind, imported.Changed...)
+
+	return imported, key, nil
+}
+
+// changedMetadata returns new entries for the imported values that differ
+// from the current ones.
+func changedMetadata(current, imported []*metadata.Entry) ([]*metadata.Entry, error) {
+	values := map[string]*metadata.Entry{}
+	for _, entry := range current {
+		values[entry.Name] = entry
+	}
+
+	changed := []*metadata.Entry{}
+	for _, entry := range imported {
+		if entry.Deleted {
+			continue
+		}
+		if value, ok := values[entry.Name]; ok && value.Value == entry.Value && value.Typed() == entry.Typed() {
+			continue
+		}
+
+		if !entry.Typed() {
+			changed = append(changed, metadata.NewKeyValue(entry.Name, entry.Value))
+			continue
+		}
+		typed, err := metadata.NewTypedKeyValue(entry.Name, string(entry.Data))
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, typed)
+	}
+
+	return changed, nil
+}
+
+// A metadataObject is a story or scenario that metadata can be exported from
+// and imported to.
+type metadataObject struct {
+	record  *specstack.MetadataRecord
+	sourcer specification.Sourcer
+}
+
+// metadataObjects lists every story, followed by its scenarios and the
+// Examples rows of its scenario outlines.
+func metadataObjects(spec *specification.Specification) []metadataObject {
+	objects := []metadataObject{}
+	for _, story := range spec.Stories() {
+		objects = append(objects, metadataObject{
+			record: &specstack.MetadataRecord{
+				Kind: metadata.KindStory,
+				ID:   string(story.ID()),
+				Name: story.Name,
+				File: story.SourceIdentifier,
+				Line: int(story.Location.Line),
+			},
+			sourcer: story,
+		})
+
+		for _, scenario := range spec.Scenarios(story) {
+			objects = append(objects, scenarioMetadataObject(story, scenario, scenario.Name))
+			for i, example := range scenario.ExampleScenarios() {
+				name := fmt.Sprintf("%s%s%d", scenario.Name, specification.ExampleSeparator, i+1)
+				objects = append(objects, scenarioMetadataObject(story, example, name))
+			}
+		}
+	}
+	return objects
+}
+
+func scenarioMetadataObject(
+	story *specification.Story,
+	scenario *specification.Scenario,
+	name string,
+) metadataObject {
+	return metadataObject{
+		record: &specstack.MetadataRecord{
+			Kind:  metadata.KindScenario,
+			ID:    string(scenario.ID()),
+			Story: story.Name,
+			Name:  name,
+			File:  story.SourceIdentifier,
+			Line:  scenario.LineNumber(),
+		},
+		sourcer: scenario,
+	}
+}
+
+// matchMetadataObject finds the object of the same kind as a record with its
+// ID, or failing that its name, or failing that the name closest to its own.
+func matchMetadataObject(
+	objects []metadataObject,
+	record *specstack.MetadataRecord,
+) (metadataObject, string, error) {
+	candidates := []metadataObject{}
+	for _, object := range objects {
+		if object.record.Kind == record.Kind {
+			candidates = append(candidates, object)
+		}
+	}
+
+	if record.ID != "" {
+		for _, object := range candidates {
+			if object.record.ID == record.ID {
+				return object, specstack.MatchID, nil
+			}
+		}
+	}
+
+	named := []metadataObject{}
+	labels := make([]string, len(candidates))
+	for i, object := range candidates {
+		labels[i] = object.record.Label()
+		if object.record.Name == record.Name && (record.Story == "" || object.record.Story == record.Story) {
+			named = append(named, object)
+		}
+	}
+	if len(named) == 1 {
+		return named[0], specstack.MatchName, nil
+	}
+	if len(named) > 1 {
+		return metadataObject{}, "", fmt.Errorf("more than one %s is called %s", record.Kind, record.Name)
+	}
+
+	return fuzzyMatchMetadataObject(candidates, labels, record)
+}
+
+// fuzzyMatchMetadataObject finds the object whose label is close enough to the
+// record's, and clearly closer than any other.
+func fuzzyMatchMetadataObject(
+	candidates []metadataObject,
+	labels []string,
+	record *specstack.MetadataRecord,
+) (metadataObject, string, error) {
+	ranks := fuzzy.Rank(record.Label(), labels)
+	if len(ranks) == 0 || ranks[0].Rank < fuzzy.DistanceThreshold ||
+		(len(ranks) > 1 && fuzzy.Equivalent(ranks[0], ranks[1])) {
+		return metadataObject{}, "", fmt.Errorf("no %s matches", record.Kind)
+	}
+
+	for i, label := range labels {
+		if label == ranks[0].Term {
+			return candidates[i], specstack.MatchFuzzy, nil
+		}
+	}
+	return metadataObject{}, "", fmt.Errorf("no %s matches", record.Kind)
+}
+
+// readKey reads what an object's metadata is keyed by, so that it can be read
+// more than once.
+func readKey(reader specification.ReadSourcer, object specification.Sourcer) ([]byte, error) {
+	key, err := reader.ReadSource(object)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(key)
+}
+
+// StartDiscussion starts a thread about a story or scenario, returning its ID.
+func (d *Developer) StartDiscussion(target specstack.MetadataTarget, message string) (string, error) {
+	if err := d.assertWorkingTree(); err != nil {
+		return "", err
+	}
+	if message == "" {
+		return "", fmt.Errorf("a discussion needs a message")
+	}
+
+	_, object, err := d.metadataObject(target)
+	if err != nil {
+		return "", err
+	}
+
+	id, entry, err := discussion.Start(message)
+	if err != nil {
+		return "", err
+	}
+	if err := metadata.Add(d.store, object, d.stamp(entry)...); err != nil {
+		return "", err
+	}
+
+	return id, d.autoPush(entry)
+}
+
+// ReplyToDiscussion adds a message to a thread about a story or scenario.
+func (d *Developer) ReplyToDiscussion(target specstack.MetadataTarget, thread, message string) error {
+	if message == "" {
+		return fmt.Errorf("a reply needs a message")
+	}
+
+	return d.postToDiscussion(target, thread, func(t *discussion.Thread) (*metadata.Entry, error) {
+		return t.Reply(message)
+	})
+}
+
+// ResolveDiscussion marks a thread about a story or scenario as resolved.
+func (d *Developer) ResolveDiscussion(target specstack.MetadataTarget, thread string) error {
+	return d.postToDiscussion(target, thread, (*discussion.Thread).Resolve)
+}
+
+// ReopenDiscussion marks a resolved thread about a story or scenario as open.
+func (d *Developer) ReopenDiscussion(target specstack.MetadataTarget, thread string) error {
+	return d.postToDiscussion(target, thread, (*discussion.Thread).Reopen)
+}
+
+// postToDiscussion records the post made to the thread whose ID starts with
+// the given prefix.
+func (d *Developer) postToDiscussion(
+	target specstack.MetadataTarget,
+	thread string,
+	post func(*discussion.Thread) (*metadata.Entry, error),
+) error {
+	if err := d.assertWorkingTree(); err != nil {
+		return err
+	}
+
+	_, object, err := d.metadataObject(target)
+	if err != nil {
+		return err
+	}
+	key, err := ioutil.ReadAll(object)
+	if err != nil {
+		return err
+	}
+
+	threads, err := d.discussionThreads(key)
+	if err != nil {
+		return err
+	}
+	found, err := discussion.Find(threads, thread)
+	if err != nil {
+		return err
+	}
+
+	entry, err := post(found)
+	if err != nil {
+		return err
+	}
+	if err := metadata.Add(d.store, bytes.NewReader(key), d.stamp(entry)...); err != nil {
+		return err
+	}
+
+	return d.autoPush(entry)
+}
+
+// ListDiscussions returns the threads about a story or scenario, or with an
+// empty target, those about every story and scenario that has any.
+func (d *Developer) ListDiscussions(target specstack.MetadataTarget) ([]specstack.Discussion, error) {
+	if target.Actor != "" || target.Story != "" || target.Scenario != "" {
+		return d.listTargetDiscussion(target)
+	}
+
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	discussions := []specstack.Discussion{}
+	for _, object := range metadataObjects(spec) {
+		key, err := readKey(reader, object.sourcer)
+		if err != nil {
+			return nil, err
+		}
+		threads, err := d.discussionThreads(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(threads) > 0 {
+			discussions = append(discussions, specstack.Discussion{
+				Kind:    object.record.Kind,
+				Label:   object.record.Label(),
+				Threads: threads,
+			})
+		}
+	}
+
+	return discussions, nil
+}
+
+func (d *Developer) listTargetDiscussion(target specstack.MetadataTarget) ([]specstack.Discussion, error) {
+	kind, object, err := d.metadataObject(target)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ioutil.ReadAll(object)
+	if err != nil {
+		return nil, err
+	}
+
+	threads, err := d.discussionThreads(key)
+	if err != nil {
+		return nil, err
+	}
+
+	label := target.Scenario
+	switch {
+	case target.Actor != "":
+		label = target.Actor
+	case target.Step != "":
+		label = target.Scenario + "/" + target.Step
+	case target.Scenario == "":
+		label = target.Story
+	case target.Story != "":
+		label = target.Story + "/" + target.Scenario
+	}
+
+	return []specstack.Discussion{{Kind: kind, Label: label, Threads: threads}}, nil
+}
+
+func (d *Developer) discussionThreads(key []byte) ([]*discussion.Thread, error) {
+	entries, err := metadata.ReadDiscussion(d.store, bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+	return discussion.Threads(entries)
+}
+
+func (d *Developer) workflow() (*progress.Workflow, error) {
+	return progress.ParseWorkflow(d.config.Project.WorkflowDefinition())
+}
+
+// SetProgress moves a scenario to a state of the project's workflow, if the
+// workflow allows it, recording the state as the scenario's progress.
+func (d *Developer) SetProgress(target specstack.MetadataTarget, state string) error {
+	if err := d.assertWorkingTree(); err != nil {
+		return err
+	}
+
+	workflow, err := d.workflow()
+	if err != nil {
+		return err
+	}
+
+	_, object, err := d.findScenarioObject(target.Scenario, target.Story, target.Filters...)
+	if err != nil {
+		return err
+	}
+	key, err := ioutil.ReadAll(object)
+	if err != nil {
+		return err
+	}
+
+	current, err := d.scenarioProgress(workflow, key)
+	if err != nil {
+		return err
+	}
+	if err := workflow.CheckTransition(current, state); err != nil {
+		return &errors.ValidationError{E: err}
+	}
+
+	entry := metadata.NewKeyValue(progress.MetadataName, state)
+	if errs, err := d.schemaErrors(metadata.KindScenario, entry); err != nil {
+		return err
+	} else if errs.Any() {
+		return errs
+	}
+	if err := metadata.Add(d.store, bytes.NewReader(key), d.stamp(entry)...); err != nil {
+		return err
+	}
+
+	return d.autoPush(entry)
+}
+
+// scenarioProgress returns the state a scenario is in, which is the
+// workflow's first until it's moved.
+func (d *Developer) scenarioProgress(workflow *progress.Workflow, key []byte) (string, error) {
+	entries, err := metadata.ReadAll(d.store, bytes.NewReader(key))
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if entry.Name == progress.MetadataName {
+			return entry.Value, nil
+		}
+	}
+	return workflow.Initial(), nil
+}
+
+// Progress counts the scenarios in each state, per story and per actor.
+func (d *Developer) Progress() (*specstack.ProgressReport, error) {
+	workflow, err := d.workflow()
+	if err != nil {
+		return nil, err
+	}
+
+	spec, reader, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &specstack.ProgressReport{States: append([]string{}, workflow.States...)}
+	stories := map[string]map[string]int{}
+	for _, story := range spec.Stories() {
+		counts, err := d.storyProgress(workflow, reader, spec.Scenarios(story))
+		if err != nil {
+			return nil, err
+		}
+		others := []string{}
+		for state := range counts {
+			if !containsString(report.States, state) {
+				others = append(others, state)
+			}
+		}
+		sort.Strings(others)
+		report.States = append(report.States, others...)
+
+		stories[story.SourceIdentifier] = counts
+		report.Stories = append(report.Stories, specstack.ProgressCount{Name: story.Name, Counts: counts})
+	}
+
+	for _, actor := range spec.Actors() {
+		counts := map[string]int{}
+		for _, story := range actor.Stories {
+			for state, count := range stories[story.SourceIdentifier] {
+				counts[state] += count
+			}
+		}
+		report.Actors = append(report.Actors, specstack.ProgressCount{Name: actor.Name, Counts: counts})
+	}
+
+	return report, nil
+}
+
+func (d *Developer) storyProgress(
+	workflow *progress.Workflow,
+	reader specification.ReadSourcer,
+	scenarios []*specification.Scenario,
+) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, scenario := range scenarios {
+		key, err := readKey(reader, scenario)
+		if err != nil {
+			return nil, err
+		}
+		state, err := d.scenarioProgress(workflow, key)
+		if err != nil {
+			return nil, err
+		}
+		counts[state]++
+	}
+	return counts, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Developer) CheckScenarioIDs() error {
+	spec, _, err := d.specification()
+	if err != nil {
+		return err
+	}
+
+	errs := []string{}
+	for _, err := range []error{spec.CheckScenarioIDs(), spec.CheckStorySources()} {
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "\n"))
+}
+
+func (d *Developer) metadataLookup(reader specification.ReadSourcer) specification.MetadataLookup {
+	return func(object specification.Sourcer) ([]*metadata.Entry, error) {
+		key, err := reader.ReadSource(object)
+		if err != nil {
+			return nil, err
+		}
+
+		return metadata.ReadAll(d.store, key)
+	}
+}
+
+// MetadataStoryFilters narrows stories to those whose metadata matches every
+// predicate.
+func (d *Developer) MetadataStoryFilters(predicates ...*metadata.Predicate) []specification.QueryMapFunc {
+	reader := d.specificationFactory().SpecificationReader()
+	return specification.NewMetadataQueryExpression(predicates...).StoryFilters(d.metadataLookup(reader))
+}
+
+// MetadataScenarioFilters narrows scenarios to those whose metadata matches
+// every predicate.
+func (d *Developer) MetadataScenarioFilters(predicates ...*metadata.Predicate) []specification.QueryMapFunc {
+	reader := d.specificationFactory().SpecificationReader()
+	return specification.NewMetadataQueryExpression(predicates...).ScenarioFilters(d.metadataLookup(reader))
+}
+
+func (d *Developer) StoryFilters(expression string) ([]specification.QueryMapFunc, error) {
+	query, err := specification.ParseQueryExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := d.specificationFactory().SpecificationReader()
+	return query.StoryFilters(d.metadataLookup(reader)), nil
+}
+
+func (d *Developer) ScenarioFilters(expression string) ([]specification.QueryMapFunc, error) {
+	query, err := specification.ParseQueryExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := d.specificationFactory().SpecificationReader()
+	return query.ScenarioFilters(d.metadataLookup(reader)), nil
+}
+
+func (d *Developer) QueryStories(filters ...specification.QueryMapFunc) ([]*specification.Story, error) {
+	spec, _, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	stories := specification.NewQuery(spec).MapReduce(filters...).Stories()
+	if stories == nil {
+		stories = spec.Stories()
+	}
+	return stories, nil
+}
+
+func (d *Developer) QueryScenarios(filters ...specification.QueryMapFunc) ([]*specification.Scenario, error) {
+	spec, _, err := d.specification()
+	if err != nil {
+		return nil, err
+	}
+
+	q := specification.NewQuery(spec).MapReduce(filters...)
+	if q.Scenarios() == nil {
+		q.MapReduce(specification.MapScenarioMatchFunc(func(*specification.Scenario) bool { return true }))
+	}
+	return q.MapReduce(specification.MapScenarioFileOrder()).Scenarios(), nil
+}
+
+// DiffSpecification compares the specification at two revisions. An empty
+// revision stands for the specification currently being read, which is the
+// working tree unless a revision has been selected.
+func (d *Developer) DiffSpecification(from, to string) ([]specification.ScenarioChange, error) {
+	fromSpec, err := d.specificationAt(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toSpec, err := d.specificationAt(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return specification.DiffSpecifications(fromSpec, toSpec), nil
+}
+
+func (d *Developer) specificationAt(revision string) (*specification.Specification, error) {
+	factory := d.specificationFactory()
+
+	if revision != "" {
+		hash, err := d.repo.ResolveRevision(revision)
+		if err != nil {
+			return nil, err
+		}
+		factory.Revision = hash
+		factory.RevisionSourcer = d.repo
+	}
+
+	spec, _, err := factory.Specification()
+	return spec, err
+}
+
+func (d *Developer) Pull() (*repository.MetadataMerge, error) {
+	if d.config.Project.Remote == "" {
+		return nil, fmt.Errorf("configure a project remote first")
+	}
+	return metadata.Pull(d.repo, d.config.Project.Remote)
+}
+
+func (d *Developer) Push() error {
+	return d.push(context.Background())
+}
+
+func (d *Developer) push(ctx context.Context) error {
+	if d.config.Project.Remote == "" {
+		return fmt.Errorf("configure a project remote first")
+	}
+	err := metadata.Push(ctx, d.repo, d.config.Project.Remote)
+	if err == repository.ErrMetadataPushRejected {
+		return fmt.Errorf("%s, run spec pull before pushing again", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Everything is pushed at once, so nothing is left waiting.
+	outbox, err := d.outbox()
+	if err != nil {
+		return err
+	}
+	return outbox.Clear()
+}
+
+// InstallHooks makes the git hooks run spec, chaining into any hooks that are
+// already there.
+func (d *Developer) InstallHooks() ([]repository.HookStatus, error) {
+	return d.repo.InstallHooks()
+}
+
+// UninstallHooks stops the git hooks running spec.
+func (d *Developer) UninstallHooks() ([]repository.HookStatus, error) {
+	return d.repo.UninstallHooks()
+}
+
+// HookStatuses reports whether each git hook runs spec.
+func (d *Developer) HookStatuses() ([]repository.HookStatus, error) {
+	return d.repo.HookStatuses()
+}
+
+func (d *Developer) TransferScenarioMetadata() error {
+	// Snapshots track the working tree, so there is nothing to transfer when
+	// reading a past revision.
+	if d.revision != "" {
+		return nil
+	}
+
 	ss := snapshot.NewScenarioMetadataSnapshotter(
 		d.specificationFactory(),
 		d.store,
 		"snapshots",
 		d.repo,
-		d.config.Project.FeaturesDir,
+		specification.IgnoreBackground(),
 	)
 	return ss.Snapshot()
 }
@@ -268,7 +1508,7 @@ func (d *Developer) RepoPostMergeHook() error {
 	if d.config.Project.PullingMode != config.ModeSemiAuto {
 		return nil
 	}
-	if err := d.Pull(); err != nil {
+	if _, err := d.Pull(); err != nil {
 		return err
 	}
 	return d.TransferScenarioMetadata()
@@ -0,0 +1,117 @@
+// Package progress models the lifecycle of scenarios as a workflow of states,
+// recorded in their metadata.
+package progress
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetadataName names the metadata that records the state of a scenario. It
+// is namespaced so as not to take over metadata projects already call
+// progress.
+const MetadataName = "spec.progress"
+
+// DefaultWorkflow is used by projects that don't define their own.
+const DefaultWorkflow = "todo>in-progress>review>done,review>in-progress"
+
+// Separators of a workflow definition.
+const (
+	pathSeparator       = ","
+	transitionSeparator = ">"
+)
+
+// A Workflow is the states a scenario can be in, and the transitions allowed
+// between them. Scenarios start in the first state.
+type Workflow struct {
+	States      []string
+	transitions map[string][]string
+}
+
+// ParseWorkflow reads a workflow from comma separated paths of states joined
+// by ">", each allowing the transitions from one state to the next, e.g.
+// "todo>doing>done,done>doing".
+func ParseWorkflow(definition string) (*Workflow, error) {
+	workflow := &Workflow{transitions: map[string][]string{}}
+
+	for _, path := range strings.Split(definition, pathSeparator) {
+		states := strings.Split(path, transitionSeparator)
+		if len(states) < 2 {
+			return nil, fmt.Errorf("invalid workflow %q, expected transitions such as todo>done", definition)
+		}
+
+		for i, state := range states {
+			state = strings.TrimSpace(state)
+			if state == "" {
+				return nil, fmt.Errorf("invalid workflow %q, states cannot be blank", definition)
+			}
+			workflow.add(state)
+			if i > 0 {
+				workflow.allow(strings.TrimSpace(states[i-1]), state)
+			}
+		}
+	}
+
+	return workflow, nil
+}
+
+func (w *Workflow) add(state string) {
+	if !w.Has(state) {
+		w.States = append(w.States, state)
+	}
+}
+
+func (w *Workflow) allow(from, to string) {
+	for _, allowed := range w.transitions[from] {
+		if allowed == to {
+			return
+		}
+	}
+	w.transitions[from] = append(w.transitions[from], to)
+}
+
+// Initial returns the state scenarios start in.
+func (w *Workflow) Initial() string {
+	return w.States[0]
+}
+
+// Has reports whether the workflow has a state.
+func (w *Workflow) Has(state string) bool {
+	for _, s := range w.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the states that can follow a state.
+func (w *Workflow) Next(state string) []string {
+	return w.transitions[state]
+}
+
+// CheckTransition fails unless the workflow allows moving from one state to
+// another. A scenario in a state the workflow no longer has can move to any
+// of its states.
+func (w *Workflow) CheckTransition(from, to string) error {
+	if !w.Has(to) {
+		return fmt.Errorf("unknown state %s, expected one of %s", to, strings.Join(w.States, ", "))
+	}
+	if from == to {
+		return fmt.Errorf("already %s", to)
+	}
+	if !w.Has(from) {
+		return nil
+	}
+
+	for _, next := range w.Next(from) {
+		if next == to {
+			return nil
+		}
+	}
+
+	if len(w.Next(from)) == 0 {
+		return fmt.Errorf("cannot move from %s, it is a final state", from)
+	}
+	return fmt.Errorf("cannot move from %s to %s, expected one of %s", from, to, strings.Join(w.Next(from), ", "))
+}
@@ -0,0 +1,43 @@
+package progress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TheDefaultWorkflowIsValid(t *testing.T) {
+	workflow, err := ParseWorkflow(DefaultWorkflow)
+	require.Nil(t, err)
+	require.Equal(t, []string{"todo", "in-progress", "review", "done"}, workflow.States)
+	require.Equal(t, "todo", workflow.Initial())
+	require.Equal(t, []string{"done", "in-progress"}, workflow.Next("review"))
+}
+
+func Test_InvalidWorkflowsAreRejected(t *testing.T) {
+	for definition, message := range map[string]string{
+		"":           `invalid workflow "", expected transitions such as todo>done`,
+		"todo":       `invalid workflow "todo", expected transitions such as todo>done`,
+		"todo>>done": `invalid workflow "todo>>done", states cannot be blank`,
+	} {
+		_, err := ParseWorkflow(definition)
+		require.EqualError(t, err, message, definition)
+	}
+}
+
+func Test_OnlyTheWorkflowsTransitionsAreAllowed(t *testing.T) {
+	workflow, err := ParseWorkflow("todo > doing > done, doing > todo")
+	require.Nil(t, err)
+
+	require.Nil(t, workflow.CheckTransition("todo", "doing"))
+	require.Nil(t, workflow.CheckTransition("doing", "todo"))
+	require.Nil(t, workflow.CheckTransition("retired", "done"))
+
+	require.EqualError(t, workflow.CheckTransition("todo", "done"),
+		"cannot move from todo to done, expected one of doing")
+	require.EqualError(t, workflow.CheckTransition("done", "todo"),
+		"cannot move from done, it is a final state")
+	require.EqualError(t, workflow.CheckTransition("todo", "todo"), "already todo")
+	require.EqualError(t, workflow.CheckTransition("todo", "blocked"),
+		"unknown state blocked, expected one of todo, doing, done")
+}
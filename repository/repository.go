@@ -1,6 +1,9 @@
 package repository
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
 // Repository represents a version control repo
 type Repository interface {
@@ -8,6 +11,9 @@ type Repository interface {
 	Configurer
 	MetadataSyncer
 	ObjectHasher
+	RevisionReader
+	StateKeeper
+	HookInstaller
 }
 
 // Initialiser initialises a repo
@@ -26,11 +32,30 @@ type Configurer interface {
 // MetadataSyncer allows for low level metadata management
 type MetadataSyncer interface {
 	PrepareMetadataSync() error
-	PullMetadata(from string) error
-	PushMetadata(to string) error
+	PullMetadata(from string) (*MetadataMerge, error)
+	PushMetadata(ctx context.Context, to string) error
 }
 
 type ObjectHasher interface {
 	ObjectHash(io.Reader) (string, error)
 	ObjectString(hash string) (string, error)
 }
+
+// RevisionReader reads files as they were at a given revision
+type RevisionReader interface {
+	ResolveRevision(revision string) (string, error)
+	RevisionFiles(revision, path string) ([]string, error)
+	RevisionFile(revision, path string) ([]byte, error)
+}
+
+// StateKeeper locates files for state that stays in the local repo
+type StateKeeper interface {
+	StatePath(name string) (string, error)
+}
+
+// HookInstaller manages the git hooks that keep metadata in sync
+type HookInstaller interface {
+	InstallHooks() ([]HookStatus, error)
+	UninstallHooks() ([]HookStatus, error)
+	HookStatuses() ([]HookStatus, error)
+}
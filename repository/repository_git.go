@@ -2,17 +2,15 @@ package repository
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"syscall"
-	"time"
 )
 
 const (
@@ -22,10 +20,17 @@ const (
 	GitConfigScopeLocal  = 1
 	GitConfigScopeSystem = 2
 	GitConfigScopeGlobal = 4
+
+	// Exit code of git config when unsetting a key that isn't set
+	gitConfigExitCodeKeyNotSet = 5
 )
 
 var ErrNoConfigFound = errors.New("no config found")
 
+// ErrMetadataPushRejected is returned when the remote has metadata that
+// hasn't been pulled, so pushing would overwrite it.
+var ErrMetadataPushRejected = errors.New("the remote has metadata that hasn't been pulled")
+
 // NewGitCmdConfigErr creates the appropriate typed error for a Git failure, if
 // possible.
 func NewGitCmdConfigErr(gitCmdErr *GitCmdErr) error {
@@ -154,6 +159,9 @@ func (repo *Git) SetConfig(key, value string) error {
 	var err error
 	if value == "" {
 		_, err = repo.runGitCommand("config", repo.configWriteScopeArg(), "--unset", key)
+		if gitErr, ok := err.(*GitCmdErr); ok && gitErr.ExitCode == gitConfigExitCodeKeyNotSet {
+			return nil
+		}
 	} else {
 		_, err = repo.runGitCommand("config", repo.configWriteScopeArg(), key, value)
 	}
@@ -277,74 +285,27 @@ func (repo *Git) SetMetadata(target io.Reader, value []byte) error {
 	return err
 }
 
-func (repo *Git) PrepareMetadataSync() error {
-	if err := repo.WriteHookFile("pre-push", "spec git-hook exec pre-push"); err != nil {
-		return err
-	}
-
-	if err := repo.WriteHookFile("post-merge", "spec git-hook exec post-merge"); err != nil {
-		return err
-	}
-
-	if err := repo.WriteHookFile("post-commit", "spec git-hook exec post-commit"); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (repo *Git) WriteHookFile(name, command string) error {
-	hooksDir, err := repo.gitHooksDirectory()
+func (repo *Git) PushMetadata(ctx context.Context, to string) error {
+	exists, err := repo.hasRemote(to)
 	if err != nil {
 		return err
 	}
-
-	path := filepath.Join(hooksDir, name)
-	if _, err := os.Stat(path); !os.IsNotExist(err) {
-		return nil
+	if !exists {
+		return NewGitConfigErr("set git remote '%s' first", to)
 	}
 
-	content := fmt.Sprintf(
-		`#!/bin/sh
-# Added by spec command on %s
-%s
-`, time.Now().Format(time.RFC3339), command)
-
-	return ioutil.WriteFile(path, []byte(content), 0774)
-}
-
-func (repo *Git) PullMetadata(from string) error {
-	exists, err := repo.hasRemote(from)
-	if err != nil {
-		return err
+	args := []string{"push", "--no-verify", to, gitNotesRef}
+	_, stderr, exitCode, err := repo.execGitCommand(ctx, nil, args...)
+	if ctx.Err() != nil {
+		return fmt.Errorf("gave up pushing to '%s': %s", to, ctx.Err())
 	}
-	if !exists {
-		return NewGitConfigErr("set git remote '%s' first", from)
+	if err != nil && strings.Contains(stderr.String(), "[rejected]") {
+		return ErrMetadataPushRejected
 	}
-
-	_, err = repo.runGitCommand(
-		"fetch",
-		from,
-		fmt.Sprintf("%s:%s", gitNotesRef, gitNotesRef),
-	)
-	return err
-}
-
-func (repo *Git) PushMetadata(to string) error {
-	exists, err := repo.hasRemote(to)
 	if err != nil {
-		return err
-	}
-	if !exists {
-		return NewGitConfigErr("set git remote '%s' first", to)
+		return NewGitCmdErr(strings.TrimSpace(stderr.String()), exitCode, args...)
 	}
-	_, err = repo.runGitCommand(
-		"push",
-		"--no-verify",
-		to,
-		gitNotesRef,
-	)
-	return err
+	return nil
 }
 
 func (repo *Git) hasRemote(name string) (bool, error) {
@@ -385,20 +346,43 @@ func (repo *Git) revList(id string) ([][]string, error) {
 	return revisions, nil
 }
 
-func (repo *Git) gitHooksDirectory() (string, error) {
+// StatePath returns the path of a file inside the git directory, where it is
+// neither committed nor pushed.
+func (repo *Git) StatePath(name string) (string, error) {
 	gitDir, err := repo.gitDirectory()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(gitDir, "hooks"), nil
+	return filepath.Join(gitDir, "specstack", name), nil
 }
 
+// gitDirectory returns the git directory shared by all of the repository's
+// working trees. It isn't always the .git of the working tree, which is a
+// file in worktrees and submodules.
 func (repo *Git) gitDirectory() (string, error) {
-	topDir, err := repo.topDirectory()
+	gitDir, err := repo.runGitCommand("rev-parse", "--git-common-dir")
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(topDir, ".git"), nil
+	return repo.absolutePath(gitDir), nil
+}
+
+// gitPath returns where git keeps a file of its own, which isn't always in
+// the .git directory of the working tree, e.g. in worktrees and submodules.
+func (repo *Git) gitPath(name string) (string, error) {
+	path, err := repo.runGitCommand("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	return repo.absolutePath(path), nil
+}
+
+// absolutePath resolves paths git gives relative to the repository.
+func (repo *Git) absolutePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(repo.path, path)
 }
 
 func (repo *Git) topDirectory() (string, error) {
@@ -406,13 +390,23 @@ func (repo *Git) topDirectory() (string, error) {
 }
 
 func (repo *Git) runGitCommandRaw(stdin io.Reader, args ...string) (string, string, int, error) {
+	stdout, stderr, exitCode, err := repo.execGitCommand(context.Background(), stdin, args...)
+
+	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), exitCode, err
+}
+
+func (repo *Git) execGitCommand(
+	ctx context.Context,
+	stdin io.Reader,
+	args ...string,
+) (*bytes.Buffer, *bytes.Buffer, int, error) {
 	finalArgs := []string{}
 	for _, arg := range args {
 		if arg != "" {
 			finalArgs = append(finalArgs, arg)
 		}
 	}
-	cmd := exec.Command("git", finalArgs...)
+	cmd := exec.CommandContext(ctx, "git", finalArgs...)
 	cmd.Dir = repo.path
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -432,7 +426,7 @@ func (repo *Git) runGitCommandRaw(stdin io.Reader, args ...string) (string, stri
 		}
 	}
 
-	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), exitCode, err
+	return &stdout, &stderr, exitCode, err
 }
 
 func (repo *Git) runGitCommand(args ...string) (string, error) {
@@ -480,3 +474,42 @@ func (repo *Git) ObjectHash(key io.Reader) (string, error) {
 func (repo *Git) ObjectString(hash string) (string, error) {
 	return repo.runGitCommand("show", hash)
 }
+
+// ResolveRevision returns the hash of the commit a branch, tag or other
+// revision points to.
+func (repo *Git) ResolveRevision(revision string) (string, error) {
+	hash, err := repo.runGitCommand("rev-parse", "--verify", "--quiet", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", revision)
+	}
+
+	return hash, nil
+}
+
+// RevisionFiles lists the files under path at the given revision. Both path
+// and the returned files are relative to the repository's working directory.
+func (repo *Git) RevisionFiles(revision, path string) ([]string, error) {
+	output, err := repo.runGitCommand("ls-tree", "-r", "-z", "--name-only", revision, "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	output = strings.TrimRight(output, "\x00")
+	if output == "" {
+		return []string{}, nil
+	}
+
+	return strings.Split(output, "\x00"), nil
+}
+
+// RevisionFile returns the exact content of a file at the given revision.
+func (repo *Git) RevisionFile(revision, path string) ([]byte, error) {
+	args := []string{"show", fmt.Sprintf("%s:./%s", revision, filepath.ToSlash(path))}
+
+	stdout, stderr, exitCode, err := repo.execGitCommand(context.Background(), nil, args...)
+	if err != nil {
+		return nil, NewGitCmdErr(strings.TrimSpace(stderr.String()), exitCode, args...)
+	}
+
+	return stdout.Bytes(), nil
+}
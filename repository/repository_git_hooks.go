@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	hookBlockStart = "# >>> specstack >>>"
+	hookBlockEnd   = "# <<< specstack <<<"
+
+	// hookDispatcherMark starts the second line of the dispatchers that run
+	// spec before a hook that couldn't have a block added to it.
+	hookDispatcherMark = "# specstack dispatcher, chains into "
+	hookChainedSuffix  = ".pre-specstack"
+
+	// legacyHookMark is written by earlier versions, which wrote whole hook
+	// files rather than blocks.
+	legacyHookMark = "# Added by spec command on"
+
+	// hooksOptOut is the state file left by uninstalling the hooks, which
+	// stops them being installed again on the next command.
+	hooksOptOut = "hooks-uninstalled"
+)
+
+// Hooks lists the git hooks spec runs in.
+var Hooks = []string{"pre-push", "post-merge", "post-commit"}
+
+// HookState describes what a hook file holds.
+type HookState string
+
+const (
+	// HookMissing means there is no hook file.
+	HookMissing HookState = "missing"
+	// HookOther means there is a hook that doesn't run spec.
+	HookOther HookState = "other"
+	// HookInstalled means the hook only runs spec.
+	HookInstalled HookState = "installed"
+	// HookAdded means a block running spec was added to another hook.
+	HookAdded HookState = "added"
+	// HookChained means a dispatcher runs spec, then the hook it replaced.
+	HookChained HookState = "chained"
+	// HookLegacy means the hook was written by an earlier version of spec.
+	HookLegacy HookState = "legacy"
+)
+
+// HookStatus reports what a hook file holds.
+type HookStatus struct {
+	Name  string
+	Path  string
+	State HookState
+}
+
+// Runs reports whether the hook runs spec.
+func (s HookStatus) Runs() bool {
+	return s.State != HookMissing && s.State != HookOther
+}
+
+func hookCommand(name string) string {
+	return "spec git-hook exec " + name
+}
+
+// hookBlock runs a command in a hook. Hooks that run before git does
+// something stop it when the command fails; other hooks carry on regardless.
+func hookBlock(name, command string) string {
+	if strings.HasPrefix(name, "pre-") {
+		command += " || exit $?"
+	}
+	return fmt.Sprintf("%s\n%s\n%s\n", hookBlockStart, command, hookBlockEnd)
+}
+
+func hookState(content string) HookState {
+	switch {
+	case content == "":
+		return HookMissing
+	case strings.Contains(content, hookDispatcherMark):
+		return HookChained
+	case strings.Contains(content, legacyHookMark):
+		return HookLegacy
+	case !strings.Contains(content, hookBlockStart):
+		return HookOther
+	case isEmptyHook(removeHookBlock(content)):
+		return HookInstalled
+	}
+	return HookAdded
+}
+
+// isEmptyHook reports whether a hook does nothing but name its shell.
+func isEmptyHook(content string) bool {
+	return strings.TrimSpace(content) == "#!/bin/sh"
+}
+
+// isShellScript reports whether a block can be added to a hook, which is the
+// case when the hook is run by a shell.
+func isShellScript(content string) bool {
+	firstLine := strings.SplitN(content, "\n", 2)[0]
+	if !strings.HasPrefix(firstLine, "#!") {
+		return false
+	}
+
+	interpreter := strings.Fields(strings.TrimPrefix(firstLine, "#!"))
+	if len(interpreter) == 0 {
+		return false
+	}
+	shell := filepath.Base(interpreter[0])
+	if shell == "env" && len(interpreter) > 1 {
+		shell = interpreter[1]
+	}
+
+	switch shell {
+	case "sh", "bash", "dash", "ksh", "zsh":
+		return true
+	}
+	return false
+}
+
+// addHookBlock puts the block straight after the shebang, so that it runs
+// even if the rest of the hook exits early.
+func addHookBlock(content, block string) string {
+	parts := strings.SplitN(content, "\n", 2)
+	if len(parts) == 1 {
+		return parts[0] + "\n" + block
+	}
+	return parts[0] + "\n" + block + parts[1]
+}
+
+/*
+removeLegacyHookLines removes the lines earlier versions wrote to a hook,
+leaving any that have been added to it since.
+*/
+func removeLegacyHookLines(name, content string) string {
+	kept := []string{}
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, legacyHookMark) || trimmed == hookCommand(name) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "")
+}
+
+func removeHookBlock(content string) string {
+	kept := []string{}
+	inBlock := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case hookBlockStart:
+			inBlock = true
+			continue
+		case hookBlockEnd:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
+/*
+gitHooksDirectory returns the directory git runs hooks from. This is the
+hooks directory in the git directory, unless core.hooksPath points
+elsewhere, as it does for some hook managers.
+*/
+func (repo *Git) gitHooksDirectory() (string, error) {
+	return repo.gitPath("hooks")
+}
+
+func (repo *Git) hookStatus(name string) (HookStatus, string, error) {
+	hooksDir, err := repo.gitHooksDirectory()
+	if err != nil {
+		return HookStatus{}, "", err
+	}
+
+	status := HookStatus{Name: name, Path: filepath.Join(hooksDir, name)}
+	content, err := ioutil.ReadFile(status.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return status, "", err
+	}
+
+	status.State = hookState(string(content))
+	return status, string(content), nil
+}
+
+// HookStatuses reports what each of the hooks spec runs in holds.
+func (repo *Git) HookStatuses() ([]HookStatus, error) {
+	statuses := []HookStatus{}
+	for _, name := range Hooks {
+		status, _, err := repo.hookStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+/*
+PrepareMetadataSync writes the hooks that are missing, unless they have been
+uninstalled. Hooks that are already there are left for InstallHooks to chain
+into, as changing them on every command would be a surprise.
+*/
+func (repo *Git) PrepareMetadataSync() error {
+	optOut, err := repo.StatePath(hooksOptOut)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(optOut); err == nil {
+		return nil
+	}
+
+	for _, name := range Hooks {
+		if err := repo.WriteHookFile(name, hookCommand(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteHookFile writes a hook that runs a command, unless there is a hook
+// already.
+func (repo *Git) WriteHookFile(name, command string) error {
+	status, _, err := repo.hookStatus(name)
+	if err != nil || status.State != HookMissing {
+		return err
+	}
+
+	return repo.writeHook(status.Path, "#!/bin/sh\n"+hookBlock(name, command))
+}
+
+func (repo *Git) writeHook(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0774)
+}
+
+/*
+InstallHooks makes each hook run spec. Missing hooks are written, and
+spec's block is added to existing shell scripts. Other hooks, such as
+binaries, are moved aside and replaced by a dispatcher that runs spec and
+then the original hook.
+*/
+func (repo *Git) InstallHooks() ([]HookStatus, error) {
+	optOut, err := repo.StatePath(hooksOptOut)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(optOut); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, name := range Hooks {
+		if err := repo.installHook(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo.HookStatuses()
+}
+
+func (repo *Git) installHook(name string) error {
+	status, content, err := repo.hookStatus(name)
+	if err != nil {
+		return err
+	}
+
+	block := hookBlock(name, hookCommand(name))
+
+	switch status.State {
+	case HookMissing:
+		return repo.writeHook(status.Path, "#!/bin/sh\n"+block)
+	case HookLegacy:
+		// Earlier versions wrote shell scripts, which may have been added to
+		// since, so only their lines are swapped for the block.
+		content = removeLegacyHookLines(name, content)
+		if isEmptyHook(content) {
+			return repo.writeHook(status.Path, "#!/bin/sh\n"+block)
+		}
+		return repo.writeHook(status.Path, addHookBlock(content, block))
+	case HookOther:
+		if isShellScript(content) {
+			return repo.writeHook(status.Path, addHookBlock(content, block))
+		}
+		return repo.writeDispatcher(status, block)
+	}
+
+	return nil
+}
+
+func (repo *Git) writeDispatcher(status HookStatus, block string) error {
+	chained := status.Path + hookChainedSuffix
+	if _, err := os.Stat(chained); err == nil {
+		return fmt.Errorf("cannot chain into the %s hook, %s already exists", status.Name, chained)
+	}
+
+	if err := os.Rename(status.Path, chained); err != nil {
+		return err
+	}
+
+	return repo.writeHook(status.Path, fmt.Sprintf(
+		"#!/bin/sh\n%s%s\n%sexec \"$(dirname \"$0\")/%s\" \"$@\"\n",
+		hookDispatcherMark,
+		filepath.Base(chained),
+		block,
+		filepath.Base(chained),
+	))
+}
+
+// UninstallHooks stops each hook running spec, restoring hooks spec was
+// added to, and stops the hooks being installed again.
+func (repo *Git) UninstallHooks() ([]HookStatus, error) {
+	for _, name := range Hooks {
+		if err := repo.uninstallHook(name); err != nil {
+			return nil, err
+		}
+	}
+
+	optOut, err := repo.StatePath(hooksOptOut)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(optOut), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(optOut, []byte{}, 0644); err != nil {
+		return nil, err
+	}
+
+	return repo.HookStatuses()
+}
+
+func (repo *Git) uninstallHook(name string) error {
+	status, content, err := repo.hookStatus(name)
+	if err != nil {
+		return err
+	}
+
+	switch status.State {
+	case HookInstalled:
+		return os.Remove(status.Path)
+	case HookLegacy:
+		content = removeLegacyHookLines(name, content)
+		if isEmptyHook(content) {
+			return os.Remove(status.Path)
+		}
+		return repo.writeHook(status.Path, content)
+	case HookAdded:
+		return repo.writeHook(status.Path, removeHookBlock(content))
+	case HookChained:
+		return os.Rename(status.Path+hookChainedSuffix, status.Path)
+	}
+
+	return nil
+}
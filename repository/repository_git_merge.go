@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MetadataMerge describes how metadata pulled from a remote was combined with
+// the local metadata.
+type MetadataMerge struct {
+	Remote string
+	// UpToDate is set when the remote had nothing the local repo lacked.
+	UpToDate bool
+	// FastForward is set when there was no local work, so the remote
+	// metadata was taken as it was.
+	FastForward bool
+	// Objects counts the objects whose metadata changed.
+	Objects int
+	// Entries counts the entries added to the local metadata.
+	Entries int
+	// Conflicts counts the objects that had new metadata on both sides.
+	Conflicts int
+	// Duplicates counts the entries found on both sides of a conflict.
+	Duplicates int
+}
+
+func (repo *Git) remoteNotesRef(remote string) string {
+	return fmt.Sprintf("refs/notes/remotes/%s/specstack", remote)
+}
+
+func (repo *Git) PullMetadata(from string) (*MetadataMerge, error) {
+	exists, err := repo.hasRemote(from)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewGitConfigErr("set git remote '%s' first", from)
+	}
+
+	// Fetch into a tracking ref, so that neither side's work is lost when
+	// both have added metadata since the last pull.
+	tracking := repo.remoteNotesRef(from)
+	if _, err := repo.runGitCommand("fetch", from, fmt.Sprintf("+%s:%s", gitNotesRef, tracking)); err != nil {
+		return nil, err
+	}
+
+	return repo.mergeMetadata(from, tracking)
+}
+
+func (repo *Git) mergeMetadata(from, tracking string) (*MetadataMerge, error) {
+	merge := &MetadataMerge{Remote: from}
+
+	if _, err := repo.runGitCommand("rev-parse", "--verify", "-q", gitNotesRef); err != nil {
+		merge.FastForward = true
+		return merge, repo.takeRemoteMetadata(merge, tracking)
+	}
+
+	if repo.isAncestor(tracking, gitNotesRef) {
+		merge.UpToDate = true
+		return merge, nil
+	}
+
+	if repo.isAncestor(gitNotesRef, tracking) {
+		merge.FastForward = true
+		return merge, repo.takeRemoteMetadata(merge, tracking)
+	}
+
+	before, err := repo.noteBlobs(gitNotesRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.unionMergeNotes(merge, tracking); err != nil {
+		return nil, err
+	}
+
+	return merge, repo.countMergedEntries(merge, before)
+}
+
+func (repo *Git) takeRemoteMetadata(merge *MetadataMerge, tracking string) error {
+	before, err := repo.noteBlobs(gitNotesRef)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.runGitCommand("update-ref", gitNotesRef, tracking); err != nil {
+		return err
+	}
+	return repo.countMergedEntries(merge, before)
+}
+
+/*
+unionMergeNotes merges the tracking notes into the local notes. Git merges
+notes on objects that only one side has touched; where both sides have added
+to the notes on an object, the JSON lines of the two notes are combined and
+entries present on both sides are kept once.
+*/
+func (repo *Git) unionMergeNotes(merge *MetadataMerge, tracking string) error {
+	_, stderr, exitCode, err := repo.runGitCommandRaw(
+		nil, "notes", "--ref", gitNotesRef, "merge", "-s", "manual", "-q", tracking,
+	)
+	if err == nil {
+		return nil
+	}
+
+	if err := repo.resolveNoteConflicts(merge, tracking); err != nil {
+		// Leaving the merge unfinished would stop the next pull merging.
+		_, _ = repo.runGitCommand("notes", "--ref", gitNotesRef, "merge", "--abort")
+		if os.IsNotExist(err) {
+			// The merge failed before getting to the notes, e.g. because an
+			// earlier merge was left unfinished.
+			return NewGitCmdErr(stderr, exitCode, "notes", "merge", tracking)
+		}
+		return err
+	}
+
+	_, err = repo.runGitCommand("notes", "--ref", gitNotesRef, "merge", "--commit")
+	return err
+}
+
+// resolveNoteConflicts resolves each note git left in the notes merge
+// worktree.
+func (repo *Git) resolveNoteConflicts(merge *MetadataMerge, tracking string) error {
+	worktree, err := repo.gitPath("NOTES_MERGE_WORKTREE")
+	if err != nil {
+		return err
+	}
+
+	conflicts, err := ioutil.ReadDir(worktree)
+	if err != nil {
+		return err
+	}
+
+	for _, conflict := range conflicts {
+		if err := repo.resolveNoteConflict(merge, worktree, conflict.Name(), tracking); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *Git) resolveNoteConflict(merge *MetadataMerge, worktree, id, tracking string) error {
+	// Both sides are read from their refs, rather than from the conflict
+	// markers git leaves in the worktree.
+	local, err := repo.note(gitNotesRef, id)
+	if err != nil {
+		return err
+	}
+	remote, err := repo.note(tracking, id)
+	if err != nil {
+		return err
+	}
+
+	note, duplicates := unionNoteLines(local, remote)
+	merge.Conflicts++
+	merge.Duplicates += duplicates
+
+	return ioutil.WriteFile(filepath.Join(worktree, id), []byte(note), 0644)
+}
+
+// note reads the note on an object, which is empty when one side of a merge
+// removed it.
+func (repo *Git) note(ref, id string) (string, error) {
+	note, err := repo.runGitCommand("notes", "--ref", ref, "show", id)
+	if e, ok := err.(*GitCmdErr); ok && strings.HasPrefix(e.Stderr, "error: no note found for object") {
+		return "", nil
+	}
+	return note, err
+}
+
+/*
+unionNoteLines combines two notes in the JSON-lines format written by
+SetMetadata. Entries are compared by their content, which includes the time
+they were created, so the same entry pulled twice is only kept once.
+*/
+func unionNoteLines(local, remote string) (note string, duplicates int) {
+	seen := map[string]struct{}{}
+	lines := []string{}
+
+	for _, line := range append(noteLines(local), noteLines(remote)...) {
+		key := noteLineKey(line)
+		if _, exists := seen[key]; exists {
+			duplicates++
+			continue
+		}
+		seen[key] = struct{}{}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"), duplicates
+}
+
+func noteLines(note string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(note, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// noteLineKey gives a line's value in a compact form, so that entries which
+// only differ in their whitespace are seen as the same entry.
+func noteLineKey(line string) string {
+	var value []byte
+	if err := json.Unmarshal([]byte(line), &value); err != nil {
+		return line
+	}
+
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, value); err != nil {
+		return string(value)
+	}
+	return compacted.String()
+}
+
+func (repo *Git) isAncestor(ancestor, descendant string) bool {
+	_, err := repo.runGitCommand("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+// noteBlobs maps each object with notes in a ref to the blob holding them.
+func (repo *Git) noteBlobs(ref string) (map[string]string, error) {
+	blobs := map[string]string{}
+
+	if _, err := repo.runGitCommand("rev-parse", "--verify", "-q", ref); err != nil {
+		return blobs, nil
+	}
+
+	output, err := repo.runGitCommand("notes", "--ref", ref, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range noteLines(output) {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			blobs[fields[1]] = fields[0]
+		}
+	}
+
+	return blobs, nil
+}
+
+func (repo *Git) countMergedEntries(merge *MetadataMerge, before map[string]string) error {
+	after, err := repo.noteBlobs(gitNotesRef)
+	if err != nil {
+		return err
+	}
+
+	for id, blob := range after {
+		if before[id] == blob {
+			continue
+		}
+
+		merge.Objects++
+		merge.Entries += len(noteLines(repo.objectContent(blob))) - len(noteLines(repo.objectContent(before[id])))
+	}
+
+	return nil
+}
+
+func (repo *Git) objectContent(hash string) string {
+	if hash == "" {
+		return ""
+	}
+	content, _ := repo.ObjectString(hash)
+	return content
+}
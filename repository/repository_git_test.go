@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -198,23 +199,69 @@ func Test_AnInitialisedGitRepoCanTrackAndSetMetadataAtTheFileLevel(t *testing.T)
 	})
 }
 
+func Test_AnInitialisedGitRepoCanSetConfigToAnEmptyValue(t *testing.T) {
+
+	_, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	require.Nil(t, repo.SetConfig("specstack.never-set", ""))
+
+	require.Nil(t, repo.SetConfig("specstack.key", "value"))
+	require.Nil(t, repo.SetConfig("specstack.key", ""))
+
+	_, err := repo.GetConfig("specstack.key")
+	require.Equal(t, ErrNoConfigFound, err)
+}
+
 func Test_AnInitialisedGitRepoThrowsAnErrorOnNoRemotePullAndPush(t *testing.T) {
 
 	_, repo, shutdown := initialisedGitRepoDir(t)
 	defer shutdown()
 
 	t.Run("Pull", func(t *testing.T) {
-		err := repo.PullMetadata("doesntexist")
+		_, err := repo.PullMetadata("doesntexist")
 		require.NotNil(t, err)
 		require.Equal(t, "set git remote 'doesntexist' first", err.Error())
 	})
 	t.Run("Push", func(t *testing.T) {
-		err := repo.PushMetadata("doesntexist")
+		err := repo.PushMetadata(context.Background(), "doesntexist")
 		require.NotNil(t, err)
 		require.Equal(t, "set git remote 'doesntexist' first", err.Error())
 	})
 }
 
+func Test_AnInitialisedGitRepoGivesUpPushingWhenItsContextIsDone(t *testing.T) {
+
+	dir, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	assertGitCmd(t, repo, "", "remote", "add", "origin", filepath.Join(dir, "nowhere"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := repo.PushMetadata(ctx, "origin")
+	require.NotNil(t, err)
+	require.Equal(t, "gave up pushing to 'origin': context canceled", err.Error())
+}
+
+func Test_AnInitialisedGitRepoReportsPushesRejectedForMetadataItHasntPulled(t *testing.T) {
+
+	dir, origin, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	require.Nil(t, ioutil.WriteFile("a.txt", []byte("1"), os.ModePerm))
+	assertGitCmd(t, origin, "", "add", "a.txt")
+	assertGitCmd(t, origin, "", "commit", "-q", "-m", "a")
+	setFileMetadata(t, origin, "a.txt", "shared")
+
+	local := clonedGitRepo(t, dir, filepath.Join(dir, "local"))
+	assertGitCmd(t, local, "", "fetch", "-q", "origin", gitNotesRef+":"+gitNotesRef)
+	setFileMetadata(t, origin, "a.txt", "theirs")
+	setFileMetadata(t, local, filepath.Join(dir, "local", "a.txt"), "ours")
+
+	require.Equal(t, ErrMetadataPushRejected, local.PushMetadata(context.Background(), "origin"))
+}
+
 func Test_AnInitialisedGitRepoKnowsItsGitDirectories(t *testing.T) {
 
 	dir, repo, shutdown := initialisedGitRepoDir(t)
@@ -234,6 +281,26 @@ func Test_AnInitialisedGitRepoKnowsItsGitDirectories(t *testing.T) {
 	hooksDir, err := repo.gitHooksDirectory()
 	require.Nil(t, err)
 	require.Equal(t, expectedHooksDir, hooksDir)
+
+	t.Run("A linked worktree shares the directories of its repository", func(t *testing.T) {
+		require.Nil(t, ioutil.WriteFile("a.txt", []byte("1"), os.ModePerm))
+		assertGitCmd(t, repo, "", "add", "a.txt")
+		assertGitCmd(t, repo, "", "commit", "-q", "-m", "a")
+		assertGitCmd(t, repo, "", "worktree", "add", "-q", "--detach", filepath.Join(dir, "linked"))
+		linked := NewGitRepository(filepath.Join(dir, "linked"))
+
+		gitDir, err := linked.gitDirectory()
+		require.Nil(t, err)
+		require.Equal(t, expectedGitDir, gitDir)
+
+		hooksDir, err := linked.gitHooksDirectory()
+		require.Nil(t, err)
+		require.Equal(t, expectedHooksDir, hooksDir)
+
+		statePath, err := linked.StatePath("outbox")
+		require.Nil(t, err)
+		require.Equal(t, filepath.Join(expectedGitDir, "specstack", "outbox"), statePath)
+	})
 }
 
 func Test_AnInitialisedGitRepoCanWriteItsHooksWhenAppropriate(t *testing.T) {
@@ -310,3 +377,294 @@ func Test_AnInitialisedGitRepoCanGetObjectContentByHash(t *testing.T) {
 		require.Equal(t, "1", content)
 	})
 }
+
+func Test_AnInitialisedGitRepoCanReadFilesAtARevision(t *testing.T) {
+
+	_, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	require.Nil(t, os.MkdirAll("features", os.ModePerm))
+	require.Nil(t, ioutil.WriteFile("features/a.feature", []byte("Feature: A\n"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile("README", []byte("readme\n"), os.ModePerm))
+	assertGitCmd(t, repo, "", "add", ".")
+	assertGitCmd(t, repo, "", "commit", "-m", "Commit A")
+	assertGitCmd(t, repo, "", "tag", "v1")
+
+	require.Nil(t, ioutil.WriteFile("features/a.feature", []byte("Feature: A2\n"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile("features/b.feature", []byte("Feature: B\n"), os.ModePerm))
+	assertGitCmd(t, repo, "", "add", ".")
+	assertGitCmd(t, repo, "", "commit", "-m", "Commit B")
+
+	t.Run("Unknown revision", func(t *testing.T) {
+		_, err := repo.ResolveRevision("v2")
+		require.Equal(t, fmt.Errorf("unknown revision v2"), err)
+	})
+	t.Run("Known revision", func(t *testing.T) {
+		hash, err := repo.ResolveRevision("v1")
+		require.Nil(t, err)
+		require.Len(t, hash, 40)
+	})
+	t.Run("Files at a revision", func(t *testing.T) {
+		files, err := repo.RevisionFiles("v1", "features")
+		require.Nil(t, err)
+		require.Equal(t, []string{"features/a.feature"}, files)
+
+		files, err = repo.RevisionFiles("HEAD", "features")
+		require.Nil(t, err)
+		require.Equal(t, []string{"features/a.feature", "features/b.feature"}, files)
+	})
+	t.Run("File content at a revision", func(t *testing.T) {
+		content, err := repo.RevisionFile("v1", "features/a.feature")
+		require.Nil(t, err)
+		require.Equal(t, "Feature: A\n", string(content))
+
+		_, err = repo.RevisionFile("v1", "features/b.feature")
+		require.NotNil(t, err)
+	})
+}
+
+func clonedGitRepo(t *testing.T, remote, path string) *Git {
+	_, _, _, err := NewGitRepository(remote).runGitCommandRaw(nil, "clone", "-q", remote, path)
+	require.Nil(t, err)
+
+	repo := NewGitRepository(path)
+	assertGitCmd(t, repo, "", "config", "user.name", "SpecStack")
+	assertGitCmd(t, repo, "", "config", "user.email", "test@specstack.io")
+
+	return repo
+}
+
+func Test_AnInitialisedGitRepoMergesPulledMetadataWithItsOwn(t *testing.T) {
+
+	dir, origin, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	require.Nil(t, ioutil.WriteFile("a.txt", []byte("1"), os.ModePerm))
+	assertGitCmd(t, origin, "", "add", "a.txt")
+	assertGitCmd(t, origin, "", "commit", "-q", "-m", "a")
+	setFileMetadata(t, origin, "a.txt", "shared")
+
+	local := clonedGitRepo(t, dir, filepath.Join(dir, "local"))
+	assertGitCmd(t, local, "", "fetch", "-q", "origin", gitNotesRef+":"+gitNotesRef)
+	file := filepath.Join(dir, "local", "a.txt")
+
+	t.Run("Nothing is merged when the remote has no new metadata", func(t *testing.T) {
+		merge, err := local.PullMetadata("origin")
+		require.Nil(t, err)
+		require.True(t, merge.UpToDate)
+	})
+
+	t.Run("Metadata added on both sides is combined", func(t *testing.T) {
+		setFileMetadata(t, origin, "a.txt", "theirs")
+		setFileMetadata(t, local, file, "ours")
+
+		merge, err := local.PullMetadata("origin")
+		require.Nil(t, err)
+		require.Equal(t, &MetadataMerge{Remote: "origin", Objects: 1, Entries: 1, Conflicts: 1, Duplicates: 1}, merge)
+		require.Equal(t, []string{"shared", "ours", "theirs"}, getFileMetadata(t, local, file))
+	})
+
+	t.Run("Metadata already merged is not merged again", func(t *testing.T) {
+		merge, err := local.PullMetadata("origin")
+		require.Nil(t, err)
+		require.True(t, merge.UpToDate)
+		require.Equal(t, []string{"shared", "ours", "theirs"}, getFileMetadata(t, local, file))
+	})
+
+	t.Run("New remote metadata is taken as it is when there is no local work", func(t *testing.T) {
+		_, err := origin.runGitCommand("fetch", filepath.Join(dir, "local"), "+"+gitNotesRef+":"+gitNotesRef)
+		require.Nil(t, err)
+		setFileMetadata(t, origin, "a.txt", "more")
+
+		merge, err := local.PullMetadata("origin")
+		require.Nil(t, err)
+		require.Equal(t, &MetadataMerge{Remote: "origin", FastForward: true, Objects: 1, Entries: 1}, merge)
+		require.Equal(t, []string{"shared", "ours", "theirs", "more"}, getFileMetadata(t, local, file))
+	})
+
+	t.Run("Metadata is combined in a linked worktree", func(t *testing.T) {
+		assertGitCmd(t, local, "", "worktree", "add", "-q", "--detach", filepath.Join(dir, "linked"))
+		linked := NewGitRepository(filepath.Join(dir, "linked"))
+		setFileMetadata(t, origin, "a.txt", "theirs again")
+		setFileMetadata(t, local, file, "ours again")
+
+		merge, err := linked.PullMetadata("origin")
+		require.Nil(t, err)
+		require.Equal(t, 1, merge.Conflicts)
+		require.Equal(t,
+			[]string{"shared", "ours", "theirs", "more", "ours again", "theirs again"},
+			getFileMetadata(t, local, file),
+		)
+	})
+}
+
+func Test_UnionNoteLinesKeepsEachEntryOnce(t *testing.T) {
+	note, duplicates := unionNoteLines("\"YQ==\"\n\"Yg==\"", "\"Yg==\"\n\"Yw==\"\n")
+
+	require.Equal(t, "\"YQ==\"\n\"Yg==\"\n\"Yw==\"", note)
+	require.Equal(t, 1, duplicates)
+}
+
+func Test_AnInitialisedGitRepoReadsMissingNotesAsEmpty(t *testing.T) {
+
+	_, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	require.Nil(t, ioutil.WriteFile("a.txt", []byte("1"), os.ModePerm))
+	id, err := repo.runGitCommand("hash-object", "-w", "a.txt")
+	require.Nil(t, err)
+
+	note, err := repo.note(gitNotesRef, id)
+	require.Nil(t, err)
+	require.Equal(t, "", note)
+
+	_, err = repo.note(gitNotesRef, "not-an-object")
+	require.NotNil(t, err)
+}
+
+func Test_AnInitialisedGitRepoChainsIntoExistingHooks(t *testing.T) {
+
+	_, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	hooksDir, err := repo.gitHooksDirectory()
+	require.Nil(t, err)
+
+	shellHook := "#!/bin/bash\nset -e\necho custom\nexit 0\n"
+	binaryHook := "#!/usr/bin/env python3\nprint('custom')\n"
+	require.Nil(t, os.MkdirAll(hooksDir, os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(hooksDir, "pre-push"), []byte(shellHook), 0774))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(hooksDir, "post-commit"), []byte(binaryHook), 0774))
+
+	readHook := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(hooksDir, name))
+		require.Nil(t, err)
+		return string(content)
+	}
+	states := func(statuses []HookStatus) (states []HookState) {
+		for _, status := range statuses {
+			states = append(states, status.State)
+		}
+		return
+	}
+
+	t.Run("Existing hooks are left alone when preparing", func(t *testing.T) {
+		require.Nil(t, repo.PrepareMetadataSync())
+
+		statuses, err := repo.HookStatuses()
+		require.Nil(t, err)
+		require.Equal(t, []HookState{HookOther, HookInstalled, HookOther}, states(statuses))
+	})
+
+	t.Run("Installing adds a block to shell scripts and chains into other hooks", func(t *testing.T) {
+		statuses, err := repo.InstallHooks()
+		require.Nil(t, err)
+		require.Equal(t, []HookState{HookAdded, HookInstalled, HookChained}, states(statuses))
+
+		require.Equal(
+			t,
+			"#!/bin/bash\n"+
+				hookBlockStart+"\nspec git-hook exec pre-push || exit $?\n"+hookBlockEnd+"\n"+
+				"set -e\necho custom\nexit 0\n",
+			readHook("pre-push"),
+		)
+		require.Contains(t, readHook("post-commit"), "spec git-hook exec post-commit\n")
+		require.Contains(t, readHook("post-commit"), `exec "$(dirname "$0")/post-commit.pre-specstack" "$@"`)
+		require.Equal(t, binaryHook, readHook("post-commit.pre-specstack"))
+	})
+
+	t.Run("Installing twice changes nothing", func(t *testing.T) {
+		before := readHook("pre-push")
+
+		statuses, err := repo.InstallHooks()
+		require.Nil(t, err)
+		require.Equal(t, []HookState{HookAdded, HookInstalled, HookChained}, states(statuses))
+		require.Equal(t, before, readHook("pre-push"))
+	})
+
+	t.Run("Uninstalling restores the existing hooks", func(t *testing.T) {
+		statuses, err := repo.UninstallHooks()
+		require.Nil(t, err)
+		require.Equal(t, []HookState{HookOther, HookMissing, HookOther}, states(statuses))
+
+		require.Equal(t, shellHook, readHook("pre-push"))
+		require.Equal(t, binaryHook, readHook("post-commit"))
+		_, err = os.Stat(filepath.Join(hooksDir, "post-commit.pre-specstack"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Uninstalled hooks are not prepared again", func(t *testing.T) {
+		require.Nil(t, repo.PrepareMetadataSync())
+
+		statuses, err := repo.HookStatuses()
+		require.Nil(t, err)
+		require.Equal(t, []HookState{HookOther, HookMissing, HookOther}, states(statuses))
+	})
+}
+
+func Test_AnInitialisedGitRepoWritesHooksToTheConfiguredHooksPath(t *testing.T) {
+
+	dir, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	assertGitCmd(t, repo, "", "config", "core.hooksPath", ".githooks")
+
+	statuses, err := repo.InstallHooks()
+	require.Nil(t, err)
+	require.Equal(t, filepath.Join(dir, ".githooks", "pre-push"), statuses[0].Path)
+
+	_, err = os.Stat(filepath.Join(dir, ".githooks", "pre-push"))
+	require.Nil(t, err)
+}
+
+func Test_AnInitialisedGitRepoUpgradesHooksFromEarlierVersions(t *testing.T) {
+
+	_, repo, shutdown := initialisedGitRepoDir(t)
+	defer shutdown()
+
+	hooksDir, err := repo.gitHooksDirectory()
+	require.Nil(t, err)
+
+	legacy := "#!/bin/sh\n" + legacyHookMark + " 2018-01-01T00:00:00Z\nspec git-hook exec pre-push\n"
+	require.Nil(t, os.MkdirAll(hooksDir, os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(hooksDir, "pre-push"), []byte(legacy), 0774))
+
+	statuses, err := repo.HookStatuses()
+	require.Nil(t, err)
+	require.Equal(t, HookLegacy, statuses[0].State)
+
+	statuses, err = repo.InstallHooks()
+	require.Nil(t, err)
+	require.Equal(t, HookInstalled, statuses[0].State)
+
+	t.Run("Lines added to a hook since are kept", func(t *testing.T) {
+		path := filepath.Join(hooksDir, "post-merge")
+		custom := "echo custom\n"
+		legacy := "#!/bin/sh\n" + legacyHookMark + " 2018-01-01T00:00:00Z\nspec git-hook exec post-merge\n" + custom
+		require.Nil(t, ioutil.WriteFile(path, []byte(legacy), 0774))
+
+		statuses, err := repo.InstallHooks()
+		require.Nil(t, err)
+		require.Equal(t, HookAdded, statuses[1].State)
+		content, err := ioutil.ReadFile(path)
+		require.Nil(t, err)
+		require.Equal(t, "#!/bin/sh\n"+hookBlock("post-merge", hookCommand("post-merge"))+custom, string(content))
+
+		require.Nil(t, ioutil.WriteFile(path, []byte(legacy), 0774))
+		statuses, err = repo.UninstallHooks()
+		require.Nil(t, err)
+		require.Equal(t, HookOther, statuses[1].State)
+		content, err = ioutil.ReadFile(path)
+		require.Nil(t, err)
+		require.Equal(t, "#!/bin/sh\n"+custom, string(content))
+	})
+
+	t.Run("Hooks with nothing added since are removed", func(t *testing.T) {
+		path := filepath.Join(hooksDir, "pre-push")
+		require.Nil(t, ioutil.WriteFile(path, []byte(legacy), 0774))
+
+		statuses, err := repo.UninstallHooks()
+		require.Nil(t, err)
+		require.Equal(t, HookMissing, statuses[0].State)
+	})
+}
@@ -2,6 +2,7 @@
 
 package repository
 
+import context "context"
 import io "io"
 import mock "github.com/stretchr/testify/mock"
 
@@ -54,6 +55,52 @@ func (_m *MockRepository) GetConfig(_a0 string) (string, error) {
 	return r0, r1
 }
 
+// HookStatuses provides a mock function with given fields:
+func (_m *MockRepository) HookStatuses() ([]HookStatus, error) {
+	ret := _m.Called()
+
+	var r0 []HookStatus
+	if rf, ok := ret.Get(0).(func() []HookStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]HookStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InstallHooks provides a mock function with given fields:
+func (_m *MockRepository) InstallHooks() ([]HookStatus, error) {
+	ret := _m.Called()
+
+	var r0 []HookStatus
+	if rf, ok := ret.Get(0).(func() []HookStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]HookStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IsInitialised provides a mock function with given fields:
 func (_m *MockRepository) IsInitialised() bool {
 	ret := _m.Called()
@@ -125,26 +172,35 @@ func (_m *MockRepository) PrepareMetadataSync() error {
 }
 
 // PullMetadata provides a mock function with given fields: from
-func (_m *MockRepository) PullMetadata(from string) error {
+func (_m *MockRepository) PullMetadata(from string) (*MetadataMerge, error) {
 	ret := _m.Called(from)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
+	var r0 *MetadataMerge
+	if rf, ok := ret.Get(0).(func(string) *MetadataMerge); ok {
 		r0 = rf(from)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*MetadataMerge)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(from)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// PushMetadata provides a mock function with given fields: to
-func (_m *MockRepository) PushMetadata(to string) error {
-	ret := _m.Called(to)
+// PushMetadata provides a mock function with given fields: ctx, to
+func (_m *MockRepository) PushMetadata(ctx context.Context, to string) error {
+	ret := _m.Called(ctx, to)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(to)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, to)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -152,6 +208,73 @@ func (_m *MockRepository) PushMetadata(to string) error {
 	return r0
 }
 
+// ResolveRevision provides a mock function with given fields: revision
+func (_m *MockRepository) ResolveRevision(revision string) (string, error) {
+	ret := _m.Called(revision)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(revision)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevisionFile provides a mock function with given fields: revision, path
+func (_m *MockRepository) RevisionFile(revision string, path string) ([]byte, error) {
+	ret := _m.Called(revision, path)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, string) []byte); ok {
+		r0 = rf(revision, path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(revision, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevisionFiles provides a mock function with given fields: revision, path
+func (_m *MockRepository) RevisionFiles(revision string, path string) ([]string, error) {
+	ret := _m.Called(revision, path)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(revision, path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(revision, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetConfig provides a mock function with given fields: _a0, _a1
 func (_m *MockRepository) SetConfig(_a0 string, _a1 string) error {
 	ret := _m.Called(_a0, _a1)
@@ -166,6 +289,50 @@ func (_m *MockRepository) SetConfig(_a0 string, _a1 string) error {
 	return r0
 }
 
+// StatePath provides a mock function with given fields: name
+func (_m *MockRepository) StatePath(name string) (string, error) {
+	ret := _m.Called(name)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UninstallHooks provides a mock function with given fields:
+func (_m *MockRepository) UninstallHooks() ([]HookStatus, error) {
+	ret := _m.Called()
+
+	var r0 []HookStatus
+	if rf, ok := ret.Get(0).(func() []HookStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]HookStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UnsetConfig provides a mock function with given fields: _a0
 func (_m *MockRepository) UnsetConfig(_a0 string) error {
 	ret := _m.Called(_a0)
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/endiangroup/specstack/fuzzy"
 	"github.com/endiangroup/specstack/metadata"
@@ -16,12 +17,15 @@ import (
 	"github.com/spf13/afero"
 )
 
+// snapshotEntryName names the entries the snapshots are stored in.
+const snapshotEntryName = "snapshot"
+
 type ScenarioMetadataSnapshotter struct {
-	Factory     *specification.Factory
-	Store       *persistence.Store
-	StorageKey  string
-	Repository  repository.Repository
-	FeaturesDir string
+	Factory         *specification.Factory
+	Store           *persistence.Store
+	StorageKey      string
+	Repository      repository.Repository
+	DistanceOptions []specification.ScenarioDistanceOption
 }
 
 func NewScenarioMetadataSnapshotter(
@@ -29,14 +33,14 @@ func NewScenarioMetadataSnapshotter(
 	store *persistence.Store,
 	storageKey string,
 	repo repository.Repository,
-	featuresDir string,
+	distanceOptions ...specification.ScenarioDistanceOption,
 ) *ScenarioMetadataSnapshotter {
 	return &ScenarioMetadataSnapshotter{
-		Factory:     factory,
-		Store:       store,
-		StorageKey:  storageKey,
-		Repository:  repo,
-		FeaturesDir: featuresDir,
+		Factory:         factory,
+		Store:           store,
+		StorageKey:      storageKey,
+		Repository:      repo,
+		DistanceOptions: distanceOptions,
 	}
 }
 
@@ -44,9 +48,9 @@ func (s *ScenarioMetadataSnapshotter) storageKeyReader() io.Reader {
 	return bytes.NewBufferString(s.StorageKey)
 }
 
-func (s *ScenarioMetadataSnapshotter) scenarioHasMetadata(scenario *specification.Scenario) bool {
+func (s *ScenarioMetadataSnapshotter) hasMetadata(object specification.Sourcer) bool {
 	reader := s.Factory.SpecificationReader()
-	key, err := reader.ReadSource(scenario)
+	key, err := reader.ReadSource(object)
 	if err != nil {
 		return false
 	}
@@ -97,7 +101,7 @@ func (s *ScenarioMetadataSnapshotter) storeSnapshot(snap specification.Snapshot)
 	if err != nil {
 		return err
 	}
-	return metadata.Add(s.Store, s.storageKeyReader(), metadata.NewKeyValue("snapshot", string(jsn)))
+	return metadata.Add(s.Store, s.storageKeyReader(), metadata.NewKeyValue(snapshotEntryName, string(jsn)))
 }
 
 /*
@@ -112,12 +116,18 @@ Loads a scenario from a snapshot. The procedure is:
 func (s *ScenarioMetadataSnapshotter) scenarioFromSnapshot(
 	snap specification.ScenarioSnapshot,
 ) (*specification.Scenario, error) {
-	fs, err := s.fileSystemFromScenarioSnapshot(snap)
+	fs, err := s.fileSystemFromStory(snap.StoryID, snap.StorySource)
 	if err != nil {
 		return nil, err
 	}
 
-	reader := specification.NewFilesystemReader(fs, s.FeaturesDir)
+	// The story may be in any of the feature roots, so only its own directory
+	// is read.
+	reader := &specification.Filesystem{
+		Fs:      fs,
+		Path:    filepath.Dir(snap.StorySource.Body),
+		Dialect: s.Factory.Dialect,
+	}
 	spec, _, err := reader.Read()
 	if err != nil {
 		return nil, err
@@ -125,6 +135,7 @@ func (s *ScenarioMetadataSnapshotter) scenarioFromSnapshot(
 
 	scenarios := specification.NewQuery(spec).MapReduce(
 		specification.MapScenarios(),
+		specification.MapScenarioExamples(),
 		specification.MapScenarioLineNumber(snap.LineNumber),
 	).Scenarios()
 
@@ -145,7 +156,7 @@ func (s *ScenarioMetadataSnapshotter) scenarioMapFromSnapshots(
 		if err != nil {
 			continue
 		}
-		if !s.scenarioHasMetadata(scen) {
+		if !s.hasMetadata(scen) && !s.stepsHaveMetadata(scen) {
 			continue
 		}
 		key, err := reader.ReadSource(scen)
@@ -158,8 +169,36 @@ func (s *ScenarioMetadataSnapshotter) scenarioMapFromSnapshots(
 	return output, nil
 }
 
-func (s *ScenarioMetadataSnapshotter) fileSystemFromScenarioSnapshot(
-	snap specification.ScenarioSnapshot,
+// storyFromSnapshot loads a story from a snapshot in the same way as
+// scenarioFromSnapshot.
+func (s *ScenarioMetadataSnapshotter) storyFromSnapshot(
+	snap specification.StorySnapshot,
+) (*specification.Story, error) {
+	fs, err := s.fileSystemFromStory(snap.StoryID, snap.StorySource)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &specification.Filesystem{
+		Fs:      fs,
+		Path:    filepath.Dir(snap.StorySource.Body),
+		Dialect: s.Factory.Dialect,
+	}
+	spec, _, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	story, ok := spec.StorySources[snap.StorySource.Body]
+	if !ok {
+		return nil, fmt.Errorf("no story in %s", snap.StorySource.Body)
+	}
+	return story, nil
+}
+
+func (s *ScenarioMetadataSnapshotter) fileSystemFromStory(
+	storyID string,
+	source specification.Source,
 ) (afero.Fs, error) {
 	fs := afero.NewMemMapFs()
 	var (
@@ -167,16 +206,16 @@ func (s *ScenarioMetadataSnapshotter) fileSystemFromScenarioSnapshot(
 		fileContent string
 	)
 
-	fileContent, err = s.Repository.ObjectString(snap.StoryID)
+	fileContent, err = s.Repository.ObjectString(storyID)
 
-	if err != nil && snap.StorySource.Type == specification.SourceTypeFile {
-		if fc, err := ioutil.ReadFile(snap.StorySource.Body); err == nil {
+	if err != nil && source.Type == specification.SourceTypeFile {
+		if fc, err := ioutil.ReadFile(source.Body); err == nil {
 			fileContent = string(fc)
 		}
 	}
 
 	if fileContent != "" {
-		err := afero.WriteFile(fs, snap.StorySource.Body, []byte(fileContent), os.ModePerm)
+		err := afero.WriteFile(fs, source.Body, []byte(fileContent), os.ModePerm)
 		return fs, err
 	}
 
@@ -193,7 +232,7 @@ func (s *ScenarioMetadataSnapshotter) scenarioParent(
 		parentObject io.Reader
 	)
 	for k, v := range from {
-		if distance := specification.ScenarioDistance(to, v); distance >= fuzzy.DistanceThreshold &&
+		if distance := specification.ScenarioDistance(to, v, s.DistanceOptions...); distance >= fuzzy.DistanceThreshold &&
 			distance > bestDistance {
 			bestDistance = distance
 			bestParent = v
@@ -207,7 +246,14 @@ func (s *ScenarioMetadataSnapshotter) transferScenarioMetadata(
 	reader specification.Reader,
 	scenario *specification.Scenario,
 	potentialParents map[io.Reader]*specification.Scenario) error {
-	if bestParent, parentObject := s.scenarioParent(scenario, potentialParents); bestParent != nil {
+	bestParent, parentObject := s.scenarioParent(scenario, potentialParents)
+	if bestParent == nil {
+		return nil
+	}
+
+	// Metadata of scenarios with an ID follows the ID, so it only needs
+	// transferring when the ID is new.
+	if scenario.ID() == "" || !s.hasMetadata(scenario) {
 		object, err := reader.ReadSource(scenario)
 		if err != nil {
 			return err
@@ -217,15 +263,183 @@ func (s *ScenarioMetadataSnapshotter) transferScenarioMetadata(
 			return err
 		}
 	}
+
+	return s.transferStepsMetadata(reader, scenario, bestParent)
+}
+
+func (s *ScenarioMetadataSnapshotter) stepsHaveMetadata(scenario *specification.Scenario) bool {
+	for _, step := range scenario.ScenarioSteps() {
+		if s.hasMetadata(step) {
+			return true
+		}
+	}
+	return false
+}
+
+// stepParent returns the step of the parent scenario with metadata that the
+// step most likely used to be: one with the same text, or else the one with
+// the most similar text, if any is close enough.
+func (s *ScenarioMetadataSnapshotter) stepParent(
+	to *specification.Step,
+	parent *specification.Scenario,
+) *specification.Step {
+	var (
+		bestDistance float64
+		bestParent   *specification.Step
+	)
+	for _, step := range parent.ScenarioSteps() {
+		if !s.hasMetadata(step) {
+			continue
+		}
+		if step.Text == to.Text {
+			return step
+		}
+		if distance := specification.StepDistance(to, step); distance >= fuzzy.DistanceThreshold &&
+			distance > bestDistance {
+			bestDistance = distance
+			bestParent = step
+		}
+	}
+	return bestParent
+}
+
+func (s *ScenarioMetadataSnapshotter) transferStepsMetadata(
+	reader specification.Reader,
+	scenario, parent *specification.Scenario,
+) error {
+	for _, step := range scenario.ScenarioSteps() {
+		if s.hasMetadata(step) {
+			continue
+		}
+		parentStep := s.stepParent(step, parent)
+		if parentStep == nil {
+			continue
+		}
+
+		parentObject, err := reader.ReadSource(parentStep)
+		if err != nil {
+			return err
+		}
+		object, err := reader.ReadSource(step)
+		if err != nil {
+			return err
+		}
+		if err := s.transferMetadata(parentObject, object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+storyParent returns the removed story that the story most likely used to be:
+the one read from the same file if there is one, or else the one with the
+most similar narrative, if any is close enough.
+*/
+func (s *ScenarioMetadataSnapshotter) storyParent(
+	to *specification.Story,
+	from []*specification.Story,
+) *specification.Story {
+	var (
+		bestDistance float64
+		bestParent   *specification.Story
+	)
+	for _, story := range from {
+		if story.SourceIdentifier == to.SourceIdentifier {
+			return story
+		}
+		if distance := specification.StoryDistance(to, story); distance >= fuzzy.DistanceThreshold &&
+			distance > bestDistance {
+			bestDistance = distance
+			bestParent = story
+		}
+	}
+	return bestParent
+}
+
+/*
+transferStoryMetadata gives a story without metadata that of the story it used
+to be. Story metadata used to be keyed by the content of the story's file, so
+that is tried first.
+*/
+func (s *ScenarioMetadataSnapshotter) transferStoryMetadata(
+	reader specification.Reader,
+	story *specification.Story,
+	potentialParents []*specification.Story,
+) error {
+	if s.hasMetadata(story) {
+		return nil
+	}
+
+	var parent specification.Sourcer = story.FileSource()
+	if !s.hasMetadata(parent) {
+		bestParent := s.storyParent(story, potentialParents)
+		if bestParent == nil {
+			return nil
+		}
+		parent = bestParent
+	}
+
+	parentObject, err := reader.ReadSource(parent)
+	if err != nil {
+		return err
+	}
+	object, err := reader.ReadSource(story)
+	if err != nil {
+		return err
+	}
+	return s.transferMetadata(parentObject, object)
+}
+
+func (s *ScenarioMetadataSnapshotter) transferStoriesMetadata(
+	reader specification.Reader,
+	removed, added []specification.StorySnapshot,
+) error {
+	removedStories := []*specification.Story{}
+	for _, snap := range removed {
+		story, err := s.storyFromSnapshot(snap)
+		if err != nil || !s.hasMetadata(story) {
+			continue
+		}
+		removedStories = append(removedStories, story)
+	}
+
+	for _, snap := range added {
+		story, err := s.storyFromSnapshot(snap)
+		if err != nil {
+			return err
+		}
+		if err := s.transferStoryMetadata(reader, story, removedStories); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *ScenarioMetadataSnapshotter) transferMetadata(fromObject, toObject io.Reader) error {
-	entries, err := metadata.ReadAll(s.Store, fromObject)
+	from, err := ioutil.ReadAll(fromObject)
+	if err != nil {
+		return err
+	}
+
+	entries, err := metadata.ReadAll(s.Store, bytes.NewReader(from))
 	if err != nil {
 		return err
 	}
-	return metadata.Add(s.Store, toObject, entries...)
+	discussion, err := metadata.ReadDiscussion(s.Store, bytes.NewReader(from))
+	if err != nil {
+		return err
+	}
+
+	// Reading a committed object can also turn up the snapshots, which
+	// belong to the snapshotter rather than to the object.
+	transferred := []*metadata.Entry{}
+	for _, entry := range entries {
+		if entry.Name != snapshotEntryName {
+			transferred = append(transferred, entry)
+		}
+	}
+	return metadata.Add(s.Store, toObject, append(transferred, discussion...)...)
 }
 
 func (s *ScenarioMetadataSnapshotter) Snapshot() error {
@@ -243,11 +457,16 @@ func (s *ScenarioMetadataSnapshotter) Snapshot() error {
 	}
 
 	removed, added := previous.Diff(current)
+	reader := s.Factory.SpecificationReader()
+
+	if err := s.transferStoriesMetadata(reader, removed.Stories, added.Stories); err != nil {
+		return err
+	}
+
 	if len(added.Scenarios) == 0 || len(removed.Scenarios) == 0 {
 		return nil
 	}
 
-	reader := s.Factory.SpecificationReader()
 	removedScenarios, err := s.scenarioMapFromSnapshots(reader, removed.Scenarios)
 	if err != nil {
 		return err
@@ -0,0 +1,260 @@
+package specification
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/endiangroup/specstack/errors"
+	"github.com/endiangroup/specstack/fuzzy"
+)
+
+// FileExtActor is the file extension of actor files.
+const FileExtActor = ".actor"
+
+// Keywords of the actor file format.
+const (
+	ActorKeyword = "Actor:"
+	GoalKeyword  = "Goal:"
+)
+
+// GoalTagPrefix marks a story tag that names the goal it serves, e.g.
+// "@goal:plan-technical-outline-for-specification".
+const GoalTagPrefix = "@goal:"
+
+var (
+	narrativeRole   = regexp.MustCompile(`(?i)^as an? (.+?)[.,]?$`)
+	narrativeIntent = regexp.MustCompile(`(?i)^(?:i want|in order) (?:to )?(.+?)[.,]?$`)
+	nonSlugRunes    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+/*
+An Actor is someone or something that the specification is written for. Actors
+are read from .actor files, which look like:
+
+	Actor: Developer
+		A person who writes and maintains the specification.
+
+		Goal: Plan technical outline for specification
+			Optional description of the goal.
+
+Stories are linked to an actor by the "As a <role>" line of their narrative,
+and to one of its goals by their "I want ..." or "In order to ..." line, or by
+a GoalTagPrefix tag.
+*/
+type Actor struct {
+	Name             string
+	Description      string
+	Goals            []*Goal
+	Stories          []*Story
+	SourceIdentifier string
+}
+
+// A Goal is something an Actor wants to achieve, served by one or more
+// stories.
+type Goal struct {
+	Name        string
+	Description string
+	Actor       *Actor
+	Stories     []*Story
+}
+
+func (a *Actor) Source() Source {
+	return Source{SourceTypeFile, a.SourceIdentifier}
+}
+
+// Slug returns the goal name in the form used by GoalTagPrefix tags.
+func (g *Goal) Slug() string {
+	return slug(g.Name)
+}
+
+func parseActor(content []byte, path string) (*Actor, error) {
+	var (
+		actor       *Actor
+		description *string
+		lineNumber  int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		case actor == nil && strings.HasPrefix(line, ActorKeyword):
+			actor = &Actor{
+				Name:             strings.TrimSpace(strings.TrimPrefix(line, ActorKeyword)),
+				SourceIdentifier: path,
+			}
+			description = &actor.Description
+
+		case actor == nil:
+			return nil, fmt.Errorf("failed to parse %s: expected '%s' on line %d", path, ActorKeyword, lineNumber)
+
+		case strings.HasPrefix(line, GoalKeyword):
+			goal := &Goal{
+				Name:  strings.TrimSpace(strings.TrimPrefix(line, GoalKeyword)),
+				Actor: actor,
+			}
+			actor.Goals = append(actor.Goals, goal)
+			description = &goal.Description
+
+		default:
+			*description = strings.TrimPrefix(*description+"\n"+line, "\n")
+		}
+	}
+
+	if actor == nil {
+		return nil, fmt.Errorf("failed to parse %s: no actor found", path)
+	}
+
+	return actor, nil
+}
+
+// Role returns the role from the "As a <role>" line of the story's
+// narrative, if it has one.
+func (s *Story) Role() string {
+	return s.narrative(narrativeRole)
+}
+
+// Intent returns what the story's narrative says is wanted, from its
+// "I want ..." or "In order to ..." line, if it has one.
+func (s *Story) Intent() string {
+	return s.narrative(narrativeIntent)
+}
+
+func (s *Story) narrative(pattern *regexp.Regexp) string {
+	if s.Feature == nil {
+		return ""
+	}
+	for _, line := range strings.Split(s.Description, "\n") {
+		if match := pattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// Actors returns all actors, in alphabetical order of the file that
+// contains them.
+func (s *Specification) Actors() []*Actor {
+	sources := []string{}
+	for source := range s.ActorSources {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	actors := []*Actor{}
+	for _, source := range sources {
+		actors = append(actors, s.ActorSources[source])
+	}
+	return actors
+}
+
+// FindActor returns the actor with the given name, ignoring case, or failing
+// that the actor whose name is most similar, if any is close enough.
+func (s *Specification) FindActor(name string) (*Actor, error) {
+	var (
+		closest     *Actor
+		closestRank float64
+	)
+	for _, actor := range s.Actors() {
+		if strings.EqualFold(actor.Name, name) {
+			return actor, nil
+		}
+		if rank := fuzzy.Strcmp(strings.ToLower(actor.Name), strings.ToLower(name)); rank > closestRank {
+			closest, closestRank = actor, rank
+		}
+	}
+
+	if closestRank < fuzzy.DistanceThreshold {
+		return nil, fmt.Errorf("no actor matching %s", name)
+	}
+	return closest, nil
+}
+
+/*
+LinkActors links every story to the actor named by its role, and to the goal
+of that actor which it serves. It returns a warning for each story whose role
+names no known actor. Specifications without any actors aren't checked.
+*/
+func (s *Specification) LinkActors() errors.Warnings {
+	warnings := errors.Warnings{}
+	if len(s.ActorSources) == 0 {
+		return warnings
+	}
+
+	for _, actor := range s.Actors() {
+		actor.Stories = nil
+		for _, goal := range actor.Goals {
+			goal.Stories = nil
+		}
+	}
+
+	for _, story := range s.Stories() {
+		role := story.Role()
+		if role == "" {
+			continue
+		}
+
+		actor := s.actorForRole(role)
+		if actor == nil {
+			warnings = warnings.Append(fmt.Errorf("%s: no actor found for role %s", story.SourceIdentifier, role))
+			continue
+		}
+
+		actor.Stories = append(actor.Stories, story)
+		if goal := actor.goalForStory(story); goal != nil {
+			goal.Stories = append(goal.Stories, story)
+		}
+	}
+
+	return warnings
+}
+
+func (s *Specification) actorForRole(role string) *Actor {
+	for _, actor := range s.Actors() {
+		if strings.EqualFold(actor.Name, role) {
+			return actor
+		}
+	}
+	return nil
+}
+
+// goalForStory returns the goal named by one of the story's tags or, failing
+// that, the goal most similar to the story's intent, if any is close enough.
+func (a *Actor) goalForStory(story *Story) *Goal {
+	for _, tag := range story.TagNames() {
+		for _, goal := range a.Goals {
+			if tag == GoalTagPrefix+goal.Slug() {
+				return goal
+			}
+		}
+	}
+
+	intent := strings.ToLower(story.Intent())
+	if intent == "" {
+		return nil
+	}
+
+	var (
+		closest     *Goal
+		closestRank float64
+	)
+	for _, goal := range a.Goals {
+		if rank := fuzzy.Strcmp(intent, strings.ToLower(goal.Name)); rank > closestRank {
+			closest, closestRank = goal, rank
+		}
+	}
+
+	if closestRank < fuzzy.DistanceThreshold {
+		return nil
+	}
+	return closest
+}
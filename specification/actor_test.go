@@ -0,0 +1,94 @@
+package specification
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/endiangroup/specstack/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const mockActorDeveloper = `# Who builds the product
+Actor: Developer
+	Someone who writes code.
+
+	Goal: Plan technical outline for specification
+		Decide how stories will be built.
+
+	Goal: Manage custom metadata
+`
+
+func Test_AnActorFileCanBeParsed(t *testing.T) {
+	actor, err := parseActor([]byte(mockActorDeveloper), "features/developer.actor")
+	require.Nil(t, err)
+
+	require.Equal(t, "Developer", actor.Name)
+	require.Equal(t, "Someone who writes code.", actor.Description)
+	require.Equal(t, Source{SourceTypeFile, "features/developer.actor"}, actor.Source())
+	require.Len(t, actor.Goals, 2)
+	require.Equal(t, "Plan technical outline for specification", actor.Goals[0].Name)
+	require.Equal(t, "Decide how stories will be built.", actor.Goals[0].Description)
+	require.Equal(t, "plan-technical-outline-for-specification", actor.Goals[0].Slug())
+	require.Equal(t, actor, actor.Goals[1].Actor)
+}
+
+func Test_AnActorFileReportsSyntaxErrors(t *testing.T) {
+	for _, test := range []struct {
+		content string
+		err     error
+	}{
+		{content: "", err: fmt.Errorf("failed to parse a.actor: no actor found")},
+		{content: "Goal: Something\n", err: fmt.Errorf("failed to parse a.actor: expected 'Actor:' on line 1")},
+	} {
+		t.Run(test.content, func(t *testing.T) {
+			actor, err := parseActor([]byte(test.content), "a.actor")
+			require.Equal(t, test.err, err)
+			require.Nil(t, actor)
+		})
+	}
+}
+
+func Test_ASpecificationLinksStoriesToActorsAndGoals(t *testing.T) {
+	spec, warnings, err := NewFilesystemReader(newSpecificationFs(t, map[string]string{
+		"features/developer.actor": mockActorDeveloper,
+		"features/metadata.feature": `Feature: Metadata
+  As a developer
+  I want to manage custom metadata
+`,
+		"features/outline.feature": `@goal:plan-technical-outline-for-specification
+Feature: Outline
+  As a Developer
+  I want something worded quite differently
+`,
+		"features/other.feature": `Feature: Other
+  As a Developer
+  I want to do something unrelated
+`,
+		"features/tester.feature": `Feature: Testing
+  As a Tester
+  I want to test things
+`,
+		"features/no_narrative.feature": `Feature: No narrative`,
+	}), "features").Read()
+	require.Nil(t, err)
+	require.Equal(t, errors.NewWarnings(
+		fmt.Errorf("features/tester.feature: no actor found for role Tester"),
+	), warnings)
+
+	names := func(stories []*Story) []string {
+		output := []string{}
+		for _, s := range stories {
+			output = append(output, s.Name)
+		}
+		return output
+	}
+
+	actor, err := spec.FindActor("developer")
+	require.Nil(t, err)
+	require.Equal(t, []string{"Metadata", "Other", "Outline"}, names(actor.Stories))
+	require.Equal(t, []string{"Outline"}, names(actor.Goals[0].Stories))
+	require.Equal(t, []string{"Metadata"}, names(actor.Goals[1].Stories))
+
+	_, err = spec.FindActor("Product owner")
+	require.Equal(t, fmt.Errorf("no actor matching Product owner"), err)
+}
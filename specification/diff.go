@@ -0,0 +1,257 @@
+package specification
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/endiangroup/specstack/fuzzy"
+)
+
+// A ScenarioChangeType describes how a scenario differs between two versions
+// of a specification.
+type ScenarioChangeType string
+
+const (
+	ScenarioAdded    ScenarioChangeType = "added"
+	ScenarioRemoved  ScenarioChangeType = "removed"
+	ScenarioModified ScenarioChangeType = "modified"
+	ScenarioRenamed  ScenarioChangeType = "renamed"
+	ScenarioMoved    ScenarioChangeType = "moved"
+)
+
+// Operations on a single line of a step diff.
+const (
+	LineUnchanged = " "
+	LineAdded     = "+"
+	LineRemoved   = "-"
+)
+
+// A LineDiff is one line of a step-level diff.
+type LineDiff struct {
+	Operation string
+	Text      string
+}
+
+/*
+A ScenarioChange is a single difference between two specifications. From is
+nil for added scenarios and To is nil for removed ones. Steps holds a
+line-by-line diff of the steps (and any Background and Examples) of
+modified and moved scenarios, and is empty when they haven't changed.
+*/
+type ScenarioChange struct {
+	Type  ScenarioChangeType
+	From  *Scenario
+	To    *Scenario
+	Steps []LineDiff
+}
+
+/*
+DiffSpecifications compares two specifications scenario by scenario. Scenarios
+that are identical in both are left out. The remaining scenarios are paired
+up with their closest match using ScenarioDistance and reported as modified,
+renamed or moved between files; anything left unpaired was added or removed.
+*/
+func DiffSpecifications(from, to *Specification, opts ...ScenarioDistanceOption) []ScenarioChange {
+	o := &scenarioDistanceOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	fromScenarios, toScenarios := diffableScenarios(from), diffableScenarios(to)
+	changes := []ScenarioChange{}
+
+	fromScenarios, toScenarios, moved := pairIdenticalScenarios(fromScenarios, toScenarios, o)
+	changes = append(changes, moved...)
+
+	fromScenarios, toScenarios, paired := pairRelatedScenarios(fromScenarios, toScenarios, opts, o)
+	changes = append(changes, paired...)
+
+	for _, s := range fromScenarios {
+		changes = append(changes, ScenarioChange{Type: ScenarioRemoved, From: s})
+	}
+	for _, s := range toScenarios {
+		changes = append(changes, ScenarioChange{Type: ScenarioAdded, To: s})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].location() < changes[j].location()
+	})
+	return changes
+}
+
+func diffableScenarios(spec *Specification) []*Scenario {
+	return NewQuery(spec).MapReduce(
+		MapScenarioMatchFunc(func(*Scenario) bool { return true }),
+		MapScenarioFileOrder(),
+	).Scenarios()
+}
+
+func (c ScenarioChange) location() string {
+	scenario := c.To
+	if scenario == nil {
+		scenario = c.From
+	}
+	return fullScenarioPath(scenario)
+}
+
+func fullScenarioPath(s *Scenario) string {
+	return fmt.Sprintf("%s:%010d", s.Story.SourceIdentifier, s.LineNumber())
+}
+
+func sameStoryFile(a, b *Scenario) bool {
+	return a.Story.SourceIdentifier == b.Story.SourceIdentifier
+}
+
+// pairIdenticalScenarios removes scenarios whose content is unchanged from
+// both lists, reporting the ones that changed file as moved.
+func pairIdenticalScenarios(
+	from, to []*Scenario,
+	o *scenarioDistanceOptions,
+) ([]*Scenario, []*Scenario, []ScenarioChange) {
+	changes := []ScenarioChange{}
+	remainingFrom := []*Scenario{}
+
+	for _, a := range from {
+		index := -1
+		for i, b := range to {
+			if reflect.DeepEqual(a.normalisedLines(!o.ignoreBackground), b.normalisedLines(!o.ignoreBackground)) {
+				index = i
+				if sameStoryFile(a, b) {
+					break
+				}
+			}
+		}
+
+		if index < 0 {
+			remainingFrom = append(remainingFrom, a)
+			continue
+		}
+
+		if !sameStoryFile(a, to[index]) {
+			changes = append(changes, ScenarioChange{Type: ScenarioMoved, From: a, To: to[index]})
+		}
+		remaining := append([]*Scenario{}, to[:index]...)
+		to = append(remaining, to[index+1:]...)
+	}
+
+	return remainingFrom, to, changes
+}
+
+type scenarioPair struct {
+	from, to int
+	distance float64
+}
+
+// pairRelatedScenarios pairs the remaining scenarios with their closest
+// related counterpart, best matches first.
+func pairRelatedScenarios(
+	from, to []*Scenario,
+	opts []ScenarioDistanceOption,
+	o *scenarioDistanceOptions,
+) ([]*Scenario, []*Scenario, []ScenarioChange) {
+	pairs := []scenarioPair{}
+	for i, a := range from {
+		for j, b := range to {
+			if distance := ScenarioDistance(a, b, opts...); distance >= fuzzy.DistanceThreshold {
+				pairs = append(pairs, scenarioPair{i, j, distance})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].distance > pairs[j].distance
+	})
+
+	changes := []ScenarioChange{}
+	usedFrom, usedTo := map[int]bool{}, map[int]bool{}
+	for _, pair := range pairs {
+		if usedFrom[pair.from] || usedTo[pair.to] {
+			continue
+		}
+		usedFrom[pair.from], usedTo[pair.to] = true, true
+		changes = append(changes, newScenarioChange(from[pair.from], to[pair.to], o))
+	}
+
+	return unusedScenarios(from, usedFrom), unusedScenarios(to, usedTo), changes
+}
+
+func unusedScenarios(scenarios []*Scenario, used map[int]bool) []*Scenario {
+	output := []*Scenario{}
+	for i, s := range scenarios {
+		if !used[i] {
+			output = append(output, s)
+		}
+	}
+	return output
+}
+
+func newScenarioChange(from, to *Scenario, o *scenarioDistanceOptions) ScenarioChange {
+	linesFrom := from.normalisedLines(!o.ignoreBackground)[1:]
+	linesTo := to.normalisedLines(!o.ignoreBackground)[1:]
+
+	change := ScenarioChange{Type: ScenarioModified, From: from, To: to}
+	if !reflect.DeepEqual(linesFrom, linesTo) {
+		change.Steps = DiffLines(linesFrom, linesTo)
+	}
+
+	switch {
+	case !sameStoryFile(from, to):
+		change.Type = ScenarioMoved
+	case change.Steps == nil:
+		change.Type = ScenarioRenamed
+	}
+
+	return change
+}
+
+// DiffLines returns a line-by-line diff that turns a into b, based on their
+// longest common subsequence.
+func DiffLines(a, b []string) []LineDiff {
+	lengths := commonSubsequenceLengths(a, b)
+
+	output := []LineDiff{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			output = append(output, LineDiff{LineUnchanged, a[i]})
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			output = append(output, LineDiff{LineRemoved, a[i]})
+			i++
+		default:
+			output = append(output, LineDiff{LineAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		output = append(output, LineDiff{LineRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		output = append(output, LineDiff{LineAdded, b[j]})
+	}
+
+	return output
+}
+
+// commonSubsequenceLengths returns a table where [i][j] is the length of the
+// longest common subsequence of a[i:] and b[j:].
+func commonSubsequenceLengths(a, b []string) [][]int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	return lengths
+}
@@ -0,0 +1,114 @@
+package specification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ASpecificationDiffClassifiesScenarioChanges(t *testing.T) {
+	from := generateAndReadSpec(t,
+		map[string]string{
+			"features/a.feature": `Feature: A
+
+  Scenario: Unchanged
+	Given a project
+	Then nothing changes
+
+  Scenario: Modified
+	Given a project directory
+	When I run a command
+	Then I should see some output
+
+  Scenario: Old name
+	Given a story with a long list of steps
+	When I rename the scenario that holds them
+	Then the steps are still the same
+
+  Scenario: Moving
+	Given a scenario in one file
+	Then it can be moved to another
+
+  Scenario: Removed entirely
+	Given something nobody needs
+`,
+		},
+	)
+	to := generateAndReadSpec(t,
+		map[string]string{
+			"features/a.feature": `Feature: A
+
+  Scenario: Unchanged
+	Given a project
+	Then nothing changes
+
+  Scenario: Modified
+	Given a project directory
+	When I run a different command
+	Then I should see some output
+
+  Scenario: New name
+	Given a story with a long list of steps
+	When I rename the scenario that holds them
+	Then the steps are still the same
+`,
+			"features/b.feature": `Feature: B
+
+  Scenario: Moving
+	Given a scenario in one file
+	Then it can be moved to another
+
+  Scenario: Brand new
+	Given an entirely unrelated feature
+	Then it is reported as added
+`,
+		},
+	)
+
+	changes := DiffSpecifications(from, to)
+
+	type summary struct {
+		changeType ScenarioChangeType
+		from, to   string
+	}
+	name := func(s *Scenario) string {
+		if s == nil {
+			return ""
+		}
+		return s.Name
+	}
+	summaries := []summary{}
+	for _, c := range changes {
+		summaries = append(summaries, summary{c.Type, name(c.From), name(c.To)})
+	}
+
+	require.Equal(t, []summary{
+		{ScenarioModified, "Modified", "Modified"},
+		{ScenarioRenamed, "Old name", "New name"},
+		{ScenarioRemoved, "Removed entirely", ""},
+		{ScenarioMoved, "Moving", "Moving"},
+		{ScenarioAdded, "", "Brand new"},
+	}, summaries)
+
+	require.Equal(t, []LineDiff{
+		{LineUnchanged, "a project directory"},
+		{LineRemoved, "I run a command"},
+		{LineAdded, "I run a different command"},
+		{LineUnchanged, "I should see some output"},
+	}, changes[0].Steps)
+	require.Empty(t, changes[1].Steps)
+	require.Empty(t, changes[3].Steps)
+}
+
+func Test_DiffLinesProducesAMinimalLineDiff(t *testing.T) {
+	require.Equal(t,
+		[]LineDiff{
+			{LineRemoved, "a"},
+			{LineUnchanged, "b"},
+			{LineUnchanged, "c"},
+			{LineAdded, "d"},
+		},
+		DiffLines([]string{"a", "b", "c"}, []string{"b", "c", "d"}),
+	)
+	require.Equal(t, []LineDiff{}, DiffLines(nil, nil))
+}
@@ -10,23 +10,53 @@ import (
 type Factory struct {
 	FileSystem  afero.Fs
 	FeaturesDir string
+	Roots       []Root
+	Dialect     string
 	WarningPipe io.Writer
+	Include     []string
+	Exclude     []string
+
+	// When Revision is set, the specification is read as it was at that
+	// revision using RevisionSourcer, instead of from FileSystem.
+	Revision        string
+	RevisionSourcer RevisionSourcer
 }
 
 func NewFactory(
 	fileSystem afero.Fs,
 	featuresDir string,
+	dialect string,
 	warningPipe io.Writer,
 ) *Factory {
 	return &Factory{
 		FileSystem:  fileSystem,
 		FeaturesDir: featuresDir,
+		Dialect:     dialect,
 		WarningPipe: warningPipe,
 	}
 }
 
 func (s *Factory) SpecificationReader() Reader {
-	return NewFilesystemReader(s.FileSystem, s.FeaturesDir)
+	if s.Revision != "" {
+		return &Revision{
+			Sourcer:  s.RevisionSourcer,
+			Revision: s.Revision,
+			Path:     s.FeaturesDir,
+			Roots:    s.Roots,
+			Dialect:  s.Dialect,
+			Include:  s.Include,
+			Exclude:  s.Exclude,
+		}
+	}
+
+	return &Filesystem{
+		Fs:      s.FileSystem,
+		Path:    s.FeaturesDir,
+		Roots:   s.Roots,
+		Dialect: s.Dialect,
+		Include: s.Include,
+		Exclude: s.Exclude,
+	}
 }
 
 func (s *Factory) EmitWarning(warning error) {
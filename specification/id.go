@@ -0,0 +1,203 @@
+package specification
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IDTagPrefix marks a scenario tag that gives the scenario a stable ID, e.g.
+// "@id:pay-by-card". Metadata is keyed by the ID of scenarios that have one,
+// instead of their content, so it survives rewording them.
+const IDTagPrefix = "@id:"
+
+// A ScenarioID is the stable ID of a scenario.
+type ScenarioID string
+
+// Source returns what metadata of the scenario with the ID is keyed by.
+func (id ScenarioID) Source() Source {
+	return Source{SourceTypeText, IDTagPrefix + string(id)}
+}
+
+// Example returns the ID of the Examples row at the given 1-based index of a
+// scenario outline with the ID.
+func (id ScenarioID) Example(index int) ScenarioID {
+	return ScenarioID(fmt.Sprintf("%s%s%d", id, ExampleSeparator, index))
+}
+
+// A StoryID is the stable ID of a story.
+type StoryID string
+
+// Source returns what metadata of the story with the ID is keyed by, which
+// differs from that of a scenario with the same ID.
+func (id StoryID) Source() Source {
+	return Source{SourceTypeText, "story " + IDTagPrefix + string(id)}
+}
+
+// An IDAssignment is an ID generated for a scenario that didn't have one.
+type IDAssignment struct {
+	Scenario *Scenario
+	ID       ScenarioID
+}
+
+// ID returns the ID the scenario is tagged with, if any. Examples rows of a
+// scenario outline with an ID get the outline's ID followed by their index.
+func (s *Scenario) ID() ScenarioID {
+	if s.IsExample() {
+		id := s.Outline.ID()
+		for i, example := range s.Outline.examples {
+			if id != "" && example == s {
+				return id.Example(i + 1)
+			}
+		}
+		return ""
+	}
+
+	for _, tag := range tagNames(s.Tags) {
+		if strings.HasPrefix(tag, IDTagPrefix) {
+			return ScenarioID(strings.TrimPrefix(tag, IDTagPrefix))
+		}
+	}
+	return ""
+}
+
+// ID returns the ID the story is tagged with, if any.
+func (s *Story) ID() StoryID {
+	for _, tag := range s.TagNames() {
+		if strings.HasPrefix(tag, IDTagPrefix) {
+			return StoryID(strings.TrimPrefix(tag, IDTagPrefix))
+		}
+	}
+	return ""
+}
+
+// MapScenarioID narrows the scenarios to the one with the given ID.
+func MapScenarioID(id ScenarioID) QueryMapFunc {
+	return MapScenarioMatchFunc(func(s *Scenario) bool {
+		return s.ID() == id
+	})
+}
+
+// CheckScenarioIDs returns an error listing every ID that more than one
+// scenario is tagged with.
+func (s *Specification) CheckScenarioIDs() error {
+	found := map[ScenarioID][]*Scenario{}
+	for _, scenario := range s.Scenarios() {
+		if id := scenario.ID(); id != "" {
+			found[id] = append(found[id], scenario)
+		}
+	}
+
+	duplicates := []string{}
+	for id, scenarios := range found {
+		if len(scenarios) < 2 {
+			continue
+		}
+		locations := []string{}
+		for _, scenario := range scenarios {
+			locations = append(locations, fmt.Sprintf("%s:%d", scenario.Story.SourceIdentifier, scenario.LineNumber()))
+		}
+		duplicates = append(duplicates, fmt.Sprintf("duplicate scenario id %s: %s", id, strings.Join(locations, ", ")))
+	}
+
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.Strings(duplicates)
+	return fmt.Errorf("%s", strings.Join(duplicates, "\n"))
+}
+
+// CheckStorySources returns an error listing the stories that would share
+// their metadata, because they have the same ID or, in different feature
+// roots, the same narrative.
+func (s *Specification) CheckStorySources() error {
+	found := map[Source][]*Story{}
+	for _, story := range s.Stories() {
+		found[story.Source()] = append(found[story.Source()], story)
+	}
+
+	duplicates := []string{}
+	for _, stories := range found {
+		if len(stories) < 2 {
+			continue
+		}
+		locations := []string{}
+		for _, story := range stories {
+			locations = append(locations, story.SourceIdentifier)
+		}
+		sort.Strings(locations)
+
+		label := "narrative " + stories[0].Name
+		if id := stories[0].ID(); id != "" {
+			label = "id " + string(id)
+		}
+		duplicates = append(duplicates, fmt.Sprintf("duplicate story %s: %s", label, strings.Join(locations, ", ")))
+	}
+
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.Strings(duplicates)
+	return fmt.Errorf("%s", strings.Join(duplicates, "\n"))
+}
+
+// NewScenarioIDs generates an ID from the name of every scenario and scenario
+// outline that doesn't have one. IDs are unique within the specification.
+func (s *Specification) NewScenarioIDs() []IDAssignment {
+	taken := map[ScenarioID]bool{}
+	for _, scenario := range s.Scenarios() {
+		taken[scenario.ID()] = true
+	}
+
+	assignments := []IDAssignment{}
+	for _, scenario := range s.Scenarios() {
+		if scenario.ID() != "" {
+			continue
+		}
+
+		base := slug(scenario.Name)
+		if base == "" {
+			base = "scenario"
+		}
+		id := ScenarioID(base)
+		for i := 2; taken[id]; i++ {
+			id = ScenarioID(fmt.Sprintf("%s-%d", base, i))
+		}
+
+		taken[id] = true
+		assignments = append(assignments, IDAssignment{scenario, id})
+	}
+
+	return assignments
+}
+
+/*
+InsertTags adds a line with a tag before each of the given 1-based line
+numbers of a feature file, indented to match the line it precedes. Used to
+tag scenarios, which Gherkin allows to have several lines of tags.
+*/
+func InsertTags(content []byte, tags map[int]string) []byte {
+	output := bytes.Buffer{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if tag, ok := tags[line]; ok {
+			indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+			output.WriteString(indent + tag + "\n")
+		}
+		output.WriteString(text + "\n")
+	}
+
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		output.Truncate(output.Len() - 1)
+	}
+
+	return output.Bytes()
+}
+
+func slug(text string) string {
+	return strings.Trim(nonSlugRunes.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
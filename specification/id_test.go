@@ -0,0 +1,113 @@
+package specification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mockFeatureWithIDs = `Feature: Checkout
+
+  @id:pay-by-card
+  Scenario: Pay by card
+    Given I have a card
+
+  Scenario: Pay by cash
+    Given I have cash
+
+  @id:refund
+  Scenario Outline: Refund <method>
+    Given I paid by <method>
+
+    Examples:
+      | method |
+      | card   |
+      | cash   |
+`
+
+func Test_ScenariosWithAnIDAreKeyedByIt(t *testing.T) {
+	spec := generateAndReadSpec(t, map[string]string{"features/checkout.feature": mockFeatureWithIDs})
+
+	card, err := spec.FindScenario("@id:pay-by-card", "")
+	require.Nil(t, err)
+	require.Equal(t, ScenarioID("pay-by-card"), card.ID())
+	require.Equal(t, Source{SourceTypeText, "@id:pay-by-card"}, card.Source())
+
+	cash, err := spec.FindScenario("Pay by cash", "")
+	require.Nil(t, err)
+	require.Equal(t, ScenarioID(""), cash.ID())
+	require.Equal(t, Source{SourceTypeText, cash.String()}, cash.Source())
+
+	refund, err := spec.FindScenario("@id:refund#2", "")
+	require.Nil(t, err)
+	require.Equal(t, ScenarioID("refund#2"), refund.ID())
+	require.Equal(t, "Refund cash", refund.Name)
+}
+
+func Test_NewScenarioIDsAreGeneratedFromNamesAndUnique(t *testing.T) {
+	spec := generateAndReadSpec(t, map[string]string{
+		"features/checkout.feature": mockFeatureWithIDs,
+		"features/more.feature": `Feature: More
+
+  Scenario: Pay by card
+  Scenario: Pay by cash
+`,
+	})
+
+	ids := map[string]ScenarioID{}
+	for _, assignment := range spec.NewScenarioIDs() {
+		ids[assignment.Scenario.Story.Name+"/"+assignment.Scenario.Name] = assignment.ID
+	}
+
+	require.Equal(t, map[string]ScenarioID{
+		"Checkout/Pay by cash": "pay-by-cash",
+		"More/Pay by card":     "pay-by-card-2",
+		"More/Pay by cash":     "pay-by-cash-2",
+	}, ids)
+}
+
+func Test_CheckScenarioIDsFailsOnDuplicates(t *testing.T) {
+	spec := generateAndReadSpec(t, map[string]string{"features/checkout.feature": mockFeatureWithIDs})
+	require.Nil(t, spec.CheckScenarioIDs())
+
+	spec = generateAndReadSpec(t, map[string]string{
+		"features/checkout.feature": mockFeatureWithIDs,
+		"features/more.feature":     "Feature: More\n\n  @id:refund\n  Scenario: Refund\n",
+	})
+	require.Equal(t,
+		"duplicate scenario id refund: features/checkout.feature:11, features/more.feature:4",
+		spec.CheckScenarioIDs().Error(),
+	)
+}
+
+func Test_CheckStorySourcesFailsOnStoriesSharingMetadata(t *testing.T) {
+	fs := newSpecificationFs(t, map[string]string{
+		"features/checkout.feature": "Feature: Checkout\n  As a shopper\n",
+		"billing/checkout.feature":  "Feature: Checkout\n  As a shopper\n",
+		"billing/refund.feature":    "@id:pay\nFeature: Refund\n",
+		"features/pay.feature":      "@id:pay\nFeature: Pay\n",
+	})
+
+	reader := &Filesystem{Fs: fs, Roots: []Root{{Path: "features"}}}
+	spec, _, err := reader.Read()
+	require.Nil(t, err)
+	require.Nil(t, spec.CheckStorySources())
+
+	reader.Roots = append(reader.Roots, Root{Path: "billing", Label: "billing"})
+	spec, _, err = reader.Read()
+	require.Nil(t, err)
+	require.Equal(t,
+		"duplicate story id pay: billing/refund.feature, features/pay.feature\n"+
+			"duplicate story narrative Checkout: billing/checkout.feature, features/checkout.feature",
+		spec.CheckStorySources().Error(),
+	)
+}
+
+func Test_InsertTagsAddsIndentedTagLines(t *testing.T) {
+	content := "Feature: A\n\n  Scenario: B\n    Given C\n\n\tScenario: D"
+
+	require.Equal(t,
+		"Feature: A\n\n  @id:b\n  Scenario: B\n    Given C\n\n\t@id:d\n\tScenario: D",
+		string(InsertTags([]byte(content), map[int]string{3: "@id:b", 6: "@id:d"})),
+	)
+}
@@ -0,0 +1,153 @@
+package specification
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the name of the files that list paths the reader should
+// leave out, using the same pattern syntax as .gitignore. They are read from
+// the project directory and from any directory inside a feature root.
+const IgnoreFileName = ".specignore"
+
+type ignoreRule struct {
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+/*
+IgnoreRules decide which paths are left out of a specification. Patterns
+follow .gitignore: blank lines and lines starting with "#" are skipped, a
+leading "!" re-includes a path, a trailing "/" only matches directories,
+patterns containing a "/" are relative to the directory they were declared
+in, and "*", "?" and "**" are wildcards. When several patterns match a path,
+the last one wins.
+*/
+type IgnoreRules struct {
+	rules []ignoreRule
+}
+
+// Add appends patterns that are relative to the directory base.
+func (r *IgnoreRules) Add(base string, patterns ...string) {
+	for _, line := range patterns {
+		if rule, ok := newIgnoreRule(filepath.ToSlash(base), line); ok {
+			r.rules = append(r.rules, rule)
+		}
+	}
+}
+
+// AddFile appends the patterns in the content of an ignore file found in the
+// directory base.
+func (r *IgnoreRules) AddFile(base string, content []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		r.Add(base, scanner.Text())
+	}
+}
+
+// Ignored reports whether the path should be left out.
+func (r *IgnoreRules) Ignored(filePath string, isDir bool) bool {
+	filePath = filepath.ToSlash(filePath)
+	ignored := false
+
+	for _, rule := range r.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		relative, ok := relativeToBase(rule.base, filePath)
+		if !ok || !rule.pattern.MatchString(relative) {
+			continue
+		}
+		ignored = !rule.negate
+	}
+
+	return ignored
+}
+
+// Matched reports whether any pattern matches the file or one of the
+// directories it is in, ignoring negation.
+func (r *IgnoreRules) Matched(filePath string) bool {
+	filePath = filepath.ToSlash(filePath)
+	for _, rule := range r.rules {
+		relative, ok := relativeToBase(rule.base, filePath)
+		if !ok {
+			continue
+		}
+		if !rule.dirOnly && rule.pattern.MatchString(relative) {
+			return true
+		}
+		for dir := path.Dir(relative); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if rule.pattern.MatchString(dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func relativeToBase(base, filePath string) (string, bool) {
+	base = path.Clean(base)
+	filePath = path.Clean(filePath)
+	if base == "." {
+		return filePath, true
+	}
+	if !strings.HasPrefix(filePath, base+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(filePath, base+"/"), true
+}
+
+func newIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expression := globToRegexp(line)
+	if !anchored {
+		expression = "(.*/)?" + expression
+	}
+	rule.pattern = regexp.MustCompile("^" + expression + "$")
+
+	return rule, true
+}
+
+func globToRegexp(glob string) string {
+	output := strings.Builder{}
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			output.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			output.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			output.WriteString("[^/]*")
+		case glob[i] == '?':
+			output.WriteString("[^/]")
+		default:
+			output.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,134 @@
+package specification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IgnoreRulesMatchGitignoreStylePatterns(t *testing.T) {
+	for _, test := range []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		ignored  bool
+	}{
+		{patterns: []string{"*.story"}, path: "features/a.story", ignored: true},
+		{patterns: []string{"*.story"}, path: "features/deep/a.story", ignored: true},
+		{patterns: []string{"*.story"}, path: "features/a.feature", ignored: false},
+		{patterns: []string{"# comment", ""}, path: "features/a.feature", ignored: false},
+		{patterns: []string{"drafts/"}, path: "features/drafts", isDir: true, ignored: true},
+		{patterns: []string{"drafts/"}, path: "features/drafts", isDir: false, ignored: false},
+		{patterns: []string{"/a.feature"}, path: "features/a.feature", ignored: true},
+		{patterns: []string{"/a.feature"}, path: "features/sub/a.feature", ignored: false},
+		{patterns: []string{"sub/*.feature"}, path: "features/sub/a.feature", ignored: true},
+		{patterns: []string{"sub/*.feature"}, path: "features/sub/deeper/a.feature", ignored: false},
+		{patterns: []string{"sub/**/*.feature"}, path: "features/sub/deeper/a.feature", ignored: true},
+		{patterns: []string{"?.feature"}, path: "features/a.feature", ignored: true},
+		{patterns: []string{"*.feature", "!keep.feature"}, path: "features/keep.feature", ignored: false},
+		{patterns: []string{"!keep.feature", "*.feature"}, path: "features/keep.feature", ignored: true},
+		{patterns: []string{"*.feature"}, path: "elsewhere/a.feature", ignored: false},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			rules := &IgnoreRules{}
+			rules.Add("features", test.patterns...)
+			require.Equal(t, test.ignored, rules.Ignored(test.path, test.isDir), "%v", test.patterns)
+		})
+	}
+}
+
+func Test_IncludeRulesMatchFilesInMatchingDirectories(t *testing.T) {
+	for _, test := range []struct {
+		patterns []string
+		path     string
+		matched  bool
+	}{
+		{patterns: []string{"billing/"}, path: "features/billing/pay.feature", matched: true},
+		{patterns: []string{"billing/"}, path: "features/billing/deep/pay.feature", matched: true},
+		{patterns: []string{"billing/"}, path: "features/billing.feature", matched: false},
+		{patterns: []string{"billing"}, path: "features/billing/pay.feature", matched: true},
+		{patterns: []string{"/billing/"}, path: "features/sub/billing/pay.feature", matched: false},
+		{patterns: []string{"*.feature"}, path: "features/pay.feature", matched: true},
+		{patterns: []string{"billing/"}, path: "elsewhere/billing/pay.feature", matched: false},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			rules := &IgnoreRules{}
+			rules.Add("features", test.patterns...)
+			require.Equal(t, test.matched, rules.Matched(test.path), "%v", test.patterns)
+		})
+	}
+}
+
+func Test_AFilesystemReaderLeavesOutIgnoredFiles(t *testing.T) {
+	fs := newSpecificationFs(t, map[string]string{
+		"features/.specignore":            "drafts/\n*.wip.feature\n",
+		"features/a.feature":              mockFeatureA,
+		"features/b.wip.feature":          mockFeatureA,
+		"features/drafts/c.feature":       mockFeatureA,
+		"features/vendor/.specignore":     "*\n!keep.feature\n",
+		"features/vendor/d.feature":       mockFeatureA,
+		"features/vendor/keep.feature":    mockFeatureA,
+		"features/fixtures/e.feature":     mockFeatureA,
+		"features/fixtures/f.feature":     mockFeatureA,
+		"features/api/g.feature":          mockFeatureA,
+		"features/api/internal/h.feature": mockFeatureA,
+	})
+
+	sources := func(reader *Filesystem) []string {
+		spec, _, err := reader.Read()
+		require.Nil(t, err)
+		output := []string{}
+		for _, story := range spec.Stories() {
+			output = append(output, story.SourceIdentifier)
+		}
+		return output
+	}
+
+	require.Equal(t,
+		[]string{
+			"features/a.feature",
+			"features/api/g.feature",
+			"features/api/internal/h.feature",
+			"features/fixtures/e.feature",
+			"features/fixtures/f.feature",
+			"features/vendor/keep.feature",
+		},
+		sources(&Filesystem{Fs: fs, Path: "features"}),
+	)
+
+	require.Equal(t,
+		[]string{"features/a.feature", "features/api/g.feature", "features/vendor/keep.feature"},
+		sources(&Filesystem{Fs: fs, Path: "features", Exclude: []string{"fixtures/", "internal/"}}),
+	)
+
+	require.Equal(t,
+		[]string{"features/api/g.feature", "features/api/internal/h.feature"},
+		sources(&Filesystem{Fs: fs, Path: "features", Include: []string{"api/**"}}),
+	)
+
+	require.Equal(t,
+		[]string{"features/api/g.feature", "features/api/internal/h.feature"},
+		sources(&Filesystem{Fs: fs, Path: "features", Include: []string{"api/"}}),
+	)
+}
+
+func Test_AFilesystemReaderReadsTheProjectIgnoreFile(t *testing.T) {
+	fs := newSpecificationFs(t, map[string]string{
+		".specignore":               "features/drafts/\n*.wip.feature\n",
+		"features/a.feature":        mockFeatureA,
+		"features/b.wip.feature":    mockFeatureA,
+		"features/drafts/c.feature": mockFeatureA,
+		"stories/d.feature":         mockFeatureA,
+		"stories/drafts/e.feature":  mockFeatureA,
+	})
+
+	reader := &Filesystem{Fs: fs, Roots: []Root{{Path: "features"}, {Path: "stories", Label: "stories"}}}
+	spec, _, err := reader.Read()
+	require.Nil(t, err)
+
+	output := []string{}
+	for _, story := range spec.Stories() {
+		output = append(output, story.SourceIdentifier)
+	}
+	require.Equal(t, []string{"features/a.feature", "stories/d.feature", "stories/drafts/e.feature"}, output)
+}
@@ -38,9 +38,16 @@ func (q *Query) Scenarios() []*Scenario {
 	return q.scenarios
 }
 
+// trimSource removes the root directory and file extension from a story
+// source.
 func (q *Query) trimSource(input string) string {
-	specSource := q.specification.Source + "/"
-	trimmed := strings.TrimPrefix(input, specSource)
+	trimmed := strings.TrimPrefix(input, q.specification.Source+"/")
+	for _, root := range q.specification.Roots {
+		if root.contains(input) {
+			trimmed = root.trim(input)
+			break
+		}
+	}
 	trimmed = strings.TrimSuffix(trimmed, FileExtFeature)
 	trimmed = strings.TrimSuffix(trimmed, FileExtStory)
 	return trimmed
@@ -54,10 +61,32 @@ func (q *Query) applyReduceFns(input []string, fns []QueryReduceFunc) []string {
 	return matches
 }
 
+// storyPool returns the stories already selected by the query, or every
+// story in the specification if none have been selected yet.
+func (q *Query) storyPool() []*Story {
+	if q.stories != nil {
+		return q.stories
+	}
+	return q.specification.Stories()
+}
+
+// scenarioPool returns the scenarios already selected by the query, or every
+// scenario in the selected stories if none have been selected yet. A story
+// filter that matched nothing leaves no scenarios to choose from.
+func (q *Query) scenarioPool() []*Scenario {
+	if q.scenarios != nil {
+		return q.scenarios
+	}
+	if q.stories != nil && len(q.stories) == 0 {
+		return []*Scenario{}
+	}
+	return q.specification.Scenarios(q.stories...)
+}
+
 func (q *Query) storySources() (map[string]*Story, []string) {
 	allStorySources := make(map[string]*Story)
-	for k, v := range q.specification.StorySources {
-		allStorySources[k] = v
+	for _, v := range q.storyPool() {
+		allStorySources[v.SourceIdentifier] = v
 		allStorySources[v.Name] = v
 	}
 
@@ -71,12 +100,18 @@ func (q *Query) storySources() (map[string]*Story, []string) {
 
 func MapStories(filters ...QueryReduceFunc) QueryMapFunc {
 	return func(q *Query) {
-		q.stories = []*Story{}
 		allStorySources, sources := q.storySources()
+		q.stories = []*Story{}
 
-		lookup := make(map[string]string)
+		// Stories in different roots can share a trimmed source, so each
+		// one maps to all of them.
+		sort.Strings(sources)
+		lookup := make(map[string][]string)
 		for _, source := range sources {
-			lookup[q.trimSource(source)] = source
+			trimmed := q.trimSource(source)
+			if !containsStory(allStorySources, lookup[trimmed], allStorySources[source]) {
+				lookup[trimmed] = append(lookup[trimmed], source)
+			}
 		}
 
 		finalSources := []string{}
@@ -86,11 +121,22 @@ func MapStories(filters ...QueryReduceFunc) QueryMapFunc {
 
 		matches := q.applyReduceFns(finalSources, filters)
 		for _, match := range matches {
-			q.stories = append(q.stories, allStorySources[lookup[match]])
+			for _, source := range lookup[match] {
+				q.stories = append(q.stories, allStorySources[source])
+			}
 		}
 	}
 }
 
+func containsStory(allStorySources map[string]*Story, sources []string, story *Story) bool {
+	for _, source := range sources {
+		if allStorySources[source] == story {
+			return true
+		}
+	}
+	return false
+}
+
 func MapUniqueStories() QueryMapFunc {
 	return func(q *Query) {
 		if len(q.stories) <= 1 {
@@ -122,11 +168,10 @@ func (s scenarioIndexes) find(name string) *Scenario {
 
 func MapScenarios(filters ...QueryReduceFunc) QueryMapFunc {
 	return func(q *Query) {
-		q.scenarios = []*Scenario{}
 		allScenarios := scenarioIndexes{}
 		uniqueNames := make(map[string]struct{})
 
-		for _, s := range q.specification.Scenarios(q.stories...) {
+		for _, s := range q.scenarioPool() {
 			allScenarios = append(allScenarios, scenarioIndex{s.Name, s})
 			uniqueNames[s.Name] = struct{}{}
 		}
@@ -136,6 +181,7 @@ func MapScenarios(filters ...QueryReduceFunc) QueryMapFunc {
 			pool = append(pool, k)
 		}
 
+		q.scenarios = []*Scenario{}
 		matches := q.applyReduceFns(pool, filters)
 		for _, match := range matches {
 			q.scenarios = append(q.scenarios, allScenarios.find(match))
@@ -143,6 +189,33 @@ func MapScenarios(filters ...QueryReduceFunc) QueryMapFunc {
 	}
 }
 
+// MapStoryTags narrows the query to stories whose tags match the expression.
+func MapStoryTags(expression TagExpression) QueryMapFunc {
+	return func(q *Query) {
+		stories := []*Story{}
+		for _, s := range q.storyPool() {
+			if expression.Match(s.TagNames()) {
+				stories = append(stories, s)
+			}
+		}
+		q.stories = stories
+	}
+}
+
+// MapScenarioTags narrows the query to scenarios whose tags, including those
+// inherited from their story, match the expression.
+func MapScenarioTags(expression TagExpression) QueryMapFunc {
+	return func(q *Query) {
+		scenarios := []*Scenario{}
+		for _, s := range q.scenarioPool() {
+			if expression.Match(s.TagNames()) {
+				scenarios = append(scenarios, s)
+			}
+		}
+		q.scenarios = scenarios
+	}
+}
+
 func MapScenarioIndex(index int) QueryMapFunc {
 	return func(q *Query) {
 		q.scenarios = []*Scenario{}
@@ -159,12 +232,25 @@ func MapScenarioIndex(index int) QueryMapFunc {
 	}
 }
 
+// MapScenarioExamples adds the Examples rows of any scenario outlines in the
+// query, directly after the outline they were expanded from.
+func MapScenarioExamples() QueryMapFunc {
+	return func(q *Query) {
+		scenarios := []*Scenario{}
+		for _, s := range q.scenarios {
+			scenarios = append(scenarios, s)
+			scenarios = append(scenarios, s.ExampleScenarios()...)
+		}
+		q.scenarios = scenarios
+	}
+}
+
 func MapScenarioFileOrder() QueryMapFunc {
 	fullPath := func(s *Scenario) string {
 		return fmt.Sprintf(
 			"%s:%d",
 			s.Story.SourceIdentifier,
-			s.Location.Line,
+			s.LineNumber(),
 		)
 	}
 	return func(q *Query) {
@@ -177,7 +263,7 @@ func MapScenarioFileOrder() QueryMapFunc {
 func MapScenarioMatchFunc(fn func(*Scenario) bool) QueryMapFunc {
 	return func(q *Query) {
 		newScenarios := []*Scenario{}
-		for _, s := range q.scenarios {
+		for _, s := range q.scenarioPool() {
 			if fn(s) {
 				newScenarios = append(newScenarios, s)
 			}
@@ -188,7 +274,7 @@ func MapScenarioMatchFunc(fn func(*Scenario) bool) QueryMapFunc {
 
 func MapScenarioLineNumber(line int) QueryMapFunc {
 	return MapScenarioMatchFunc(func(s *Scenario) bool {
-		return s.Node.Location.Line == line
+		return s.LineNumber() == line
 	})
 }
 
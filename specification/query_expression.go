@@ -0,0 +1,599 @@
+package specification
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/endiangroup/specstack/fuzzy"
+	"github.com/endiangroup/specstack/metadata"
+)
+
+// A MetadataLookup returns the current metadata of a story or scenario.
+type MetadataLookup func(Sourcer) ([]*metadata.Entry, error)
+
+/*
+A QueryExpression is a parsed textual query, such as:
+
+	story~"login" and tag:@api and meta:status=open and steps>10
+
+Terms are combined with "and", "or", "not" and parentheses. The supported
+terms are:
+
+	story~<text>      story name or file fuzzily matches text
+	story=<text>      story name or file is exactly text
+	scenario~<text>   scenario name fuzzily matches text
+	scenario=<text>   scenario name is exactly text
+	tag:<@tag>        story or scenario (including inherited tags) has tag
+	meta:<key>        metadata key is set
+	meta:<key>=<text> metadata key is set to text (also !=)
+	meta:<key>~<re>   metadata key matches a regular expression
+	meta:<key><op><n> metadata key compared with a number (>, >=, <, <=)
+	steps<op><n>      number of steps compared with n (>, >=, <, <=, =, !=)
+
+Text can be a bare word or a double-quoted string. When selecting stories,
+scenario terms match a story if any of its scenarios match.
+*/
+type QueryExpression struct {
+	conjuncts []queryNode
+}
+
+// ParseQueryExpression parses a textual query. An empty query matches
+// everything.
+func ParseQueryExpression(input string) (*QueryExpression, error) {
+	tokens, err := tokeniseQueryExpression(input)
+	if err != nil {
+		return nil, err
+	}
+
+	expression := &QueryExpression{}
+	if len(tokens) == 0 {
+		return expression, nil
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected '%s' in query", token.value)
+	}
+
+	expression.conjuncts = flattenQueryAnd(root)
+	return expression, nil
+}
+
+// NewMetadataQueryExpression creates an expression that matches stories or
+// scenarios whose metadata matches every predicate.
+func NewMetadataQueryExpression(predicates ...*metadata.Predicate) *QueryExpression {
+	expression := &QueryExpression{}
+	for _, predicate := range predicates {
+		expression.conjuncts = append(expression.conjuncts, queryMeta{predicate})
+	}
+	return expression
+}
+
+// StoryFilters compiles the expression into a chain of QueryMapFuncs that
+// narrow the stories in a Query.
+func (e *QueryExpression) StoryFilters(lookup MetadataLookup) []QueryMapFunc {
+	filters := []QueryMapFunc{}
+	for _, node := range e.conjuncts {
+		node := node
+		filters = append(filters, func(q *Query) {
+			ctx := &queryContext{q.specification, lookup}
+			stories := []*Story{}
+			for _, s := range q.storyPool() {
+				if node.matchStory(s, ctx) {
+					stories = append(stories, s)
+				}
+			}
+			q.stories = stories
+		})
+	}
+	return filters
+}
+
+// ScenarioFilters compiles the expression into a chain of QueryMapFuncs that
+// narrow the scenarios in a Query.
+func (e *QueryExpression) ScenarioFilters(lookup MetadataLookup) []QueryMapFunc {
+	filters := []QueryMapFunc{}
+	for _, node := range e.conjuncts {
+		node := node
+		filters = append(filters, func(q *Query) {
+			ctx := &queryContext{q.specification, lookup}
+			scenarios := []*Scenario{}
+			for _, s := range q.scenarioPool() {
+				if node.matchScenario(s, ctx) {
+					scenarios = append(scenarios, s)
+				}
+			}
+			q.scenarios = scenarios
+		})
+	}
+	return filters
+}
+
+type queryContext struct {
+	specification *Specification
+	lookup        MetadataLookup
+}
+
+type queryNode interface {
+	matchStory(*Story, *queryContext) bool
+	matchScenario(*Scenario, *queryContext) bool
+}
+
+func flattenQueryAnd(node queryNode) []queryNode {
+	if and, ok := node.(queryAnd); ok {
+		return append(flattenQueryAnd(and.left), flattenQueryAnd(and.right)...)
+	}
+	return []queryNode{node}
+}
+
+type queryAnd struct {
+	left, right queryNode
+}
+
+func (n queryAnd) matchStory(s *Story, ctx *queryContext) bool {
+	return n.left.matchStory(s, ctx) && n.right.matchStory(s, ctx)
+}
+
+func (n queryAnd) matchScenario(s *Scenario, ctx *queryContext) bool {
+	return n.left.matchScenario(s, ctx) && n.right.matchScenario(s, ctx)
+}
+
+type queryOr struct {
+	left, right queryNode
+}
+
+func (n queryOr) matchStory(s *Story, ctx *queryContext) bool {
+	return n.left.matchStory(s, ctx) || n.right.matchStory(s, ctx)
+}
+
+func (n queryOr) matchScenario(s *Scenario, ctx *queryContext) bool {
+	return n.left.matchScenario(s, ctx) || n.right.matchScenario(s, ctx)
+}
+
+type queryNot struct {
+	node queryNode
+}
+
+func (n queryNot) matchStory(s *Story, ctx *queryContext) bool {
+	return !n.node.matchStory(s, ctx)
+}
+
+func (n queryNot) matchScenario(s *Scenario, ctx *queryContext) bool {
+	return !n.node.matchScenario(s, ctx)
+}
+
+func fuzzyContains(term, value string) bool {
+	term, value = strings.ToLower(term), strings.ToLower(value)
+	return strings.Contains(value, term) || fuzzy.Strcmp(term, value) >= fuzzy.DistanceThreshold
+}
+
+func storyFileName(s *Story) string {
+	name := filepath.Base(s.SourceIdentifier)
+	return strings.TrimSuffix(strings.TrimSuffix(name, FileExtFeature), FileExtStory)
+}
+
+type queryStoryName struct {
+	fuzzy bool
+	value string
+}
+
+func (n queryStoryName) matchStory(s *Story, _ *queryContext) bool {
+	if n.fuzzy {
+		return fuzzyContains(n.value, s.Name) || fuzzyContains(n.value, storyFileName(s))
+	}
+	return s.Name == n.value || storyFileName(s) == n.value
+}
+
+func (n queryStoryName) matchScenario(s *Scenario, ctx *queryContext) bool {
+	return s.Story != nil && n.matchStory(s.Story, ctx)
+}
+
+type queryScenarioName struct {
+	fuzzy bool
+	value string
+}
+
+func (n queryScenarioName) matchStory(s *Story, ctx *queryContext) bool {
+	for _, scenario := range ctx.specification.ScenarioSources[s] {
+		if n.matchScenario(scenario, ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n queryScenarioName) matchScenario(s *Scenario, _ *queryContext) bool {
+	if n.fuzzy {
+		return fuzzyContains(n.value, s.Name)
+	}
+	return s.Name == n.value
+}
+
+type queryTag struct {
+	tag string
+}
+
+func (n queryTag) matchStory(s *Story, _ *queryContext) bool {
+	return tagLiteral(n.tag).Match(s.TagNames())
+}
+
+func (n queryTag) matchScenario(s *Scenario, _ *queryContext) bool {
+	return tagLiteral(n.tag).Match(s.TagNames())
+}
+
+type queryMeta struct {
+	predicate *metadata.Predicate
+}
+
+func (n queryMeta) match(object Sourcer, ctx *queryContext) bool {
+	if ctx.lookup == nil {
+		return false
+	}
+
+	entries, err := ctx.lookup(object)
+	if err != nil {
+		return false
+	}
+
+	return n.predicate.Match(entries)
+}
+
+func (n queryMeta) matchStory(s *Story, ctx *queryContext) bool {
+	return n.match(s, ctx)
+}
+
+func (n queryMeta) matchScenario(s *Scenario, ctx *queryContext) bool {
+	return n.match(s, ctx)
+}
+
+type querySteps struct {
+	operator string
+	value    int
+}
+
+func (n querySteps) compare(steps int) bool {
+	switch n.operator {
+	case ">":
+		return steps > n.value
+	case ">=":
+		return steps >= n.value
+	case "<":
+		return steps < n.value
+	case "<=":
+		return steps <= n.value
+	case "!=":
+		return steps != n.value
+	}
+	return steps == n.value
+}
+
+func (n querySteps) matchStory(s *Story, ctx *queryContext) bool {
+	steps := 0
+	for _, scenario := range ctx.specification.ScenarioSources[s] {
+		steps += len(scenario.Steps)
+	}
+	return n.compare(steps)
+}
+
+func (n querySteps) matchScenario(s *Scenario, _ *queryContext) bool {
+	return n.compare(len(s.Steps))
+}
+
+type queryTokenType int
+
+const (
+	queryTokenWord queryTokenType = iota
+	queryTokenString
+	queryTokenOperator
+)
+
+type queryToken struct {
+	tokenType queryTokenType
+	value     string
+}
+
+func isQueryOperatorRune(r rune) bool {
+	return strings.ContainsRune("~=!<>:()", r)
+}
+
+func tokeniseQueryExpression(input string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '"':
+			value, next, err := readQueryString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{queryTokenString, value})
+			i = next
+
+		case expectsQueryRawValue(tokens):
+			// Tag names and metadata values can hold operator runes, such as
+			// the colon in @id:login-1, so they run up to the end of the term.
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ')' {
+				i++
+			}
+			tokens = append(tokens, queryToken{queryTokenWord, string(runes[start:i])})
+
+		case isQueryOperatorRune(r):
+			operator := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && strings.ContainsRune("!<>", r) {
+				operator += "="
+			}
+			tokens = append(tokens, queryToken{queryTokenOperator, operator})
+			i += len(operator)
+
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !isQueryOperatorRune(runes[i]) && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, queryToken{queryTokenWord, string(runes[start:i])})
+		}
+	}
+
+	return tokens, nil
+}
+
+// expectsQueryRawValue reports whether the next token is the value of a
+// tag:name or meta:key<operator>value term.
+func expectsQueryRawValue(tokens []queryToken) bool {
+	isToken := func(fromEnd int, tokenType queryTokenType, value string) bool {
+		i := len(tokens) - fromEnd
+		return i >= 0 && tokens[i].tokenType == tokenType && tokens[i].value == value
+	}
+
+	if isToken(1, queryTokenOperator, ":") && isToken(2, queryTokenWord, "tag") {
+		return true
+	}
+
+	last := len(tokens) - 1
+	return last >= 0 &&
+		tokens[last].tokenType == queryTokenOperator &&
+		isQueryMetaOperator(tokens[last].value) &&
+		isToken(3, queryTokenOperator, ":") &&
+		isToken(4, queryTokenWord, "meta")
+}
+
+func readQueryString(runes []rune, start int) (string, int, error) {
+	value := strings.Builder{}
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				value.WriteRune(runes[i])
+			}
+		case '"':
+			return value.String(), i + 1, nil
+		default:
+			value.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string in query")
+}
+
+type queryParser struct {
+	tokens   []queryToken
+	position int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.position >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.position], true
+}
+
+func (p *queryParser) next() (queryToken, bool) {
+	token, ok := p.peek()
+	if ok {
+		p.position++
+	}
+	return token, ok
+}
+
+func (p *queryParser) peekKeyword(keyword string) bool {
+	token, ok := p.peek()
+	return ok && token.tokenType == queryTokenWord && token.value == keyword
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{node}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	token, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+
+	if token.tokenType == queryTokenOperator && token.value == "(" {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.next(); !ok || closing.value != ")" {
+			return nil, fmt.Errorf("missing ')' in query")
+		}
+		return node, nil
+	}
+
+	if token.tokenType != queryTokenWord {
+		return nil, fmt.Errorf("unexpected '%s' in query", token.value)
+	}
+
+	return p.parseTerm(token.value)
+}
+
+func (p *queryParser) parseTerm(field string) (queryNode, error) {
+	switch field {
+	case "story", "scenario":
+		return p.parseNameTerm(field)
+	case "tag":
+		return p.parseTagTerm()
+	case "meta":
+		return p.parseMetaTerm()
+	case "steps":
+		return p.parseStepsTerm()
+	}
+
+	return nil, fmt.Errorf("unknown query field '%s'", field)
+}
+
+func (p *queryParser) parseNameTerm(field string) (queryNode, error) {
+	operator, err := p.expectOperator(field, "~", "=")
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.expectValue(field)
+	if err != nil {
+		return nil, err
+	}
+	if field == "story" {
+		return queryStoryName{operator == "~", value}, nil
+	}
+	return queryScenarioName{operator == "~", value}, nil
+}
+
+func (p *queryParser) parseTagTerm() (queryNode, error) {
+	if _, err := p.expectOperator("tag", ":"); err != nil {
+		return nil, err
+	}
+	value, err := p.expectValue("tag")
+	if err != nil {
+		return nil, err
+	}
+	return queryTag{value}, nil
+}
+
+func (p *queryParser) parseStepsTerm() (queryNode, error) {
+	operator, err := p.expectOperator("steps", ">", ">=", "<", "<=", "=", "!=")
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.expectValue("steps")
+	if err != nil {
+		return nil, err
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("steps must be compared with a number, got '%s'", value)
+	}
+	return querySteps{operator, number}, nil
+}
+
+func (p *queryParser) parseMetaTerm() (queryNode, error) {
+	if _, err := p.expectOperator("meta", ":"); err != nil {
+		return nil, err
+	}
+	key, err := p.expectValue("meta")
+	if err != nil {
+		return nil, err
+	}
+
+	operator := metadata.OperatorExists
+	value := ""
+	if token, ok := p.peek(); ok && token.tokenType == queryTokenOperator && isQueryMetaOperator(token.value) {
+		p.next()
+		operator = token.value
+		if value, err = p.expectValue("meta"); err != nil {
+			return nil, err
+		}
+	}
+
+	predicate, err := metadata.NewPredicate(key, operator, value)
+	if err != nil {
+		return nil, err
+	}
+	return queryMeta{predicate}, nil
+}
+
+func isQueryMetaOperator(operator string) bool {
+	switch operator {
+	case metadata.OperatorEqual, metadata.OperatorNotEqual, metadata.OperatorMatches,
+		metadata.OperatorGreater, metadata.OperatorGreaterEq, metadata.OperatorLess, metadata.OperatorLessEq:
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expectOperator(field string, operators ...string) (string, error) {
+	token, ok := p.next()
+	if ok && token.tokenType == queryTokenOperator {
+		for _, operator := range operators {
+			if token.value == operator {
+				return operator, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("expected one of '%s' after %s", strings.Join(operators, "', '"), field)
+}
+
+func (p *queryParser) expectValue(field string) (string, error) {
+	token, ok := p.next()
+	if !ok || token.tokenType == queryTokenOperator {
+		return "", fmt.Errorf("expected a value for %s", field)
+	}
+	return token.value, nil
+}
@@ -0,0 +1,126 @@
+package specification
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/endiangroup/specstack/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AQueryExpressionCanFilterScenarios(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/login.feature": `@api
+Feature: Login
+
+  @id:login-1
+  Scenario: Successful login
+	Given I have an account
+	When I log in
+	Then I should see my dashboard
+
+  @wip
+  Scenario: Failed login
+	Given I have an account
+	When I log in with the wrong password
+	Then I should see an error
+	And I should not see my dashboard
+`,
+			"features/billing.feature": `Feature: Billing
+
+  Scenario: Pay an invoice
+	Given I have an invoice
+	When I pay it
+`,
+		},
+	)
+
+	lookup := func(object Sourcer) ([]*metadata.Entry, error) {
+		if scenario, ok := object.(*Scenario); ok && scenario.Name == "Failed login" {
+			return []*metadata.Entry{metadata.NewKeyValue("status", "open"), metadata.NewKeyValue("estimate", "8")}, nil
+		}
+		if scenario, ok := object.(*Scenario); ok && scenario.Name == "Pay an invoice" {
+			return []*metadata.Entry{metadata.NewKeyValue("link", "https://example.com/invoices")}, nil
+		}
+		return []*metadata.Entry{}, nil
+	}
+
+	for _, test := range []struct {
+		query     string
+		scenarios []string
+	}{
+		{query: "", scenarios: []string{"Successful login", "Failed login", "Pay an invoice"}},
+		{query: `story~"login"`, scenarios: []string{"Successful login", "Failed login"}},
+		{query: "story=Billing", scenarios: []string{"Pay an invoice"}},
+		{query: `scenario="Failed login"`, scenarios: []string{"Failed login"}},
+		{query: "tag:@api and not tag:@wip", scenarios: []string{"Successful login"}},
+		{query: "meta:status", scenarios: []string{"Failed login"}},
+		{query: "meta:status=open", scenarios: []string{"Failed login"}},
+		{query: "meta:status!=open", scenarios: []string{"Successful login", "Pay an invoice"}},
+		{query: `meta:status~"^op"`, scenarios: []string{"Failed login"}},
+		{query: "meta:estimate>5", scenarios: []string{"Failed login"}},
+		{query: "meta:estimate<=5", scenarios: []string{}},
+		{query: "tag:@id:login-1", scenarios: []string{"Successful login"}},
+		{query: "(tag:@id:login-1) or tag:@wip", scenarios: []string{"Successful login", "Failed login"}},
+		{query: "meta:link=https://example.com/invoices", scenarios: []string{"Pay an invoice"}},
+		{query: `meta:link~example.com/inv and not tag:@api`, scenarios: []string{"Pay an invoice"}},
+		{query: "steps>3", scenarios: []string{"Failed login"}},
+		{query: "steps<=2 or tag:@wip", scenarios: []string{"Failed login", "Pay an invoice"}},
+		{query: "(steps=3 or steps=2) and tag:@api", scenarios: []string{"Successful login"}},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			expression, err := ParseQueryExpression(test.query)
+			require.Nil(t, err)
+
+			query := NewQuery(spec)
+			query.MapReduce(MapScenarioMatchFunc(func(*Scenario) bool { return true }))
+			query.MapReduce(expression.ScenarioFilters(lookup)...)
+
+			names := []string{}
+			for _, s := range query.Scenarios() {
+				names = append(names, s.Name)
+			}
+			require.ElementsMatch(t, test.scenarios, names)
+		})
+	}
+}
+
+func Test_AQueryExpressionCanFilterStories(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/login.feature":   mockFeatureA,
+			"features/billing.feature": mockFeatureB,
+		},
+	)
+
+	expression, err := ParseQueryExpression("story~login")
+	require.Nil(t, err)
+
+	stories := NewQuery(spec).MapReduce(expression.StoryFilters(nil)...).Stories()
+	require.Len(t, stories, 1)
+	require.Equal(t, "features/login.feature", stories[0].SourceIdentifier)
+}
+
+func Test_AQueryExpressionReportsSyntaxErrors(t *testing.T) {
+	for _, test := range []struct {
+		query string
+		err   error
+	}{
+		{query: "story", err: fmt.Errorf("expected one of '~', '=' after story")},
+		{query: "story~", err: fmt.Errorf("expected a value for story")},
+		{query: "colour=red", err: fmt.Errorf("unknown query field 'colour'")},
+		{query: "steps>many", err: fmt.Errorf("steps must be compared with a number, got 'many'")},
+		{query: "meta:estimate>many", err: fmt.Errorf("estimate must be compared with a number, got 'many'")},
+		{query: "tag:@api and", err: fmt.Errorf("unexpected end of query")},
+		{query: "(tag:@api", err: fmt.Errorf("missing ')' in query")},
+		{query: "tag:@api tag:@wip", err: fmt.Errorf("unexpected 'tag' in query")},
+		{query: `story="login`, err: fmt.Errorf("unterminated string in query")},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			expression, err := ParseQueryExpression(test.query)
+			require.Equal(t, test.err, err)
+			require.Nil(t, expression)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package specification
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/endiangroup/snaptest"
@@ -136,3 +137,65 @@ func Test_AQueryCanAddressAScenarioByFuncFilter(t *testing.T) {
 	)
 	snaptest.Snapshot(t, filter.Scenarios())
 }
+
+func Test_AQueryCanFilterByTags(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/tagged.feature": `@core
+Feature: Tagged
+
+  @wip
+  Scenario: Work in progress
+	Then this will work
+
+  @wip @slow
+  Scenario: Slow work in progress
+	Then this will also work
+`,
+			"features/untagged.feature": mockFeatureH,
+		},
+	)
+
+	names := func(scenarios []*Scenario) []string {
+		output := []string{}
+		for _, s := range scenarios {
+			output = append(output, s.Name)
+		}
+		return output
+	}
+
+	core, err := ParseTagExpression("@core")
+	require.Nil(t, err)
+	stories := NewQuery(spec).MapReduce(MapStoryTags(core)).Stories()
+	require.Len(t, stories, 1)
+	require.Equal(t, "Tagged", stories[0].Name)
+
+	wip, err := ParseTagExpression("@wip and not @slow")
+	require.Nil(t, err)
+	require.Equal(t,
+		[]string{"Work in progress"},
+		names(NewQuery(spec).MapReduce(MapScenarioTags(wip)).Scenarios()),
+	)
+
+	inherited, err := ParseTagExpression("@core and @slow")
+	require.Nil(t, err)
+	require.Equal(t,
+		[]string{"Slow work in progress"},
+		names(NewQuery(spec).MapReduce(MapScenarioTags(inherited)).Scenarios()),
+	)
+
+	scenario, err := spec.FindScenario("work in progress", "", MapScenarioTags(wip))
+	require.Nil(t, err)
+	require.Equal(t, "Work in progress", scenario.Name)
+
+	_, err = spec.FindStory("Two scenarios", MapStoryTags(core))
+	require.Equal(t, fmt.Errorf("no story matching Two scenarios"), err)
+
+	unmatched, err := ParseTagExpression("@nomatch")
+	require.Nil(t, err)
+	require.Empty(t, NewQuery(spec).MapReduce(MapStoryTags(unmatched), MapScenarioTags(wip)).Scenarios())
+	require.Empty(t, NewQuery(spec).MapReduce(
+		MapStoryTags(unmatched),
+		MapScenarioMatchFunc(func(*Scenario) bool { return true }),
+	).Scenarios())
+}
@@ -6,11 +6,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"reflect"
 
-	// FIXME:	gherkin "github.com/cucumber/gherkin-go"
-	// OR github.com/cucumber/cucumber/gherkin/go ?
-	gherkin "github.com/DATA-DOG/godog/gherkin"
+	gherkin "github.com/cucumber/gherkin-go"
 	"github.com/endiangroup/specstack/errors"
 	"github.com/spf13/afero"
 )
@@ -21,11 +18,22 @@ const (
 	FileExtStory   = ".story"
 )
 
+// DefaultDialect is the Gherkin dialect used when none is configured. Files
+// can still override it with a "# language:" header.
+const DefaultDialect = gherkin.DEFAULT_DIALECT
+
 // A Filesystem represents a specification stored on a disk, memory, or other
-// similar entity.
+// similar entity. Files matching Exclude, or any IgnoreFileName in the tree,
+// are left out, as are files that don't match Include if it has patterns.
+// When Roots is set, each of them is read instead of Path, and merged into a
+// single specification.
 type Filesystem struct {
-	Fs   afero.Fs
-	Path string
+	Fs      afero.Fs
+	Path    string
+	Roots   []Root
+	Dialect string
+	Include []string
+	Exclude []string
 }
 
 // NewFilesystemReader creates a new Filesystem-based Source given an afero.Fs
@@ -33,8 +41,9 @@ type Filesystem struct {
 // files. It returns a Source, a list of warnings and an error.
 func NewFilesystemReader(fs afero.Fs, path string) Reader {
 	return &Filesystem{
-		Fs:   fs,
-		Path: path,
+		Fs:      fs,
+		Path:    path,
+		Dialect: DefaultDialect,
 	}
 }
 
@@ -42,13 +51,22 @@ func NewFilesystemReader(fs afero.Fs, path string) Reader {
 // possibly a fatal error.
 func (f *Filesystem) Read() (*Specification, errors.Warnings, error) {
 	spec := NewSpecification()
-	spec.Source = f.Path
+	spec.Roots = f.roots()
+	spec.Source = spec.Roots[0].Path
 	warnings := errors.Warnings{}
 
-	if err := afero.Walk(f.Fs, f.Path, f.featuresAndStoriesWalkFunc(spec, &warnings)); err != nil {
-		return nil, warnings, fmt.Errorf("failed to read directory %s: %s", f.Path, err)
+	if gherkin.GherkinDialectsBuildin().GetDialect(f.dialect()) == nil {
+		return nil, warnings, fmt.Errorf("unknown gherkin dialect %s", f.dialect())
+	}
+
+	for _, root := range spec.Roots {
+		if err := afero.Walk(f.Fs, root.Path, f.featuresAndStoriesWalkFunc(spec, root.Path, &warnings)); err != nil {
+			return nil, warnings, fmt.Errorf("failed to read directory %s: %s", root.Path, err)
+		}
 	}
 
+	warnings = append(warnings, spec.LinkActors()...)
+
 	return spec, warnings, nil
 }
 
@@ -64,13 +82,39 @@ func (f *Filesystem) ReadSource(s Sourcer) (io.Reader, error) {
 	return nil, fmt.Errorf("Unknown source type %d", source.Type)
 }
 
-func (f *Filesystem) featuresAndStoriesWalkFunc(spec *Specification, warnings *errors.Warnings) filepath.WalkFunc {
+func (f *Filesystem) roots() []Root {
+	if len(f.Roots) == 0 {
+		return []Root{{Path: f.Path}}
+	}
+	return f.Roots
+}
+
+func (f *Filesystem) featuresAndStoriesWalkFunc(
+	spec *Specification,
+	root string,
+	warnings *errors.Warnings,
+) filepath.WalkFunc {
+	ignored, included := &IgnoreRules{}, &IgnoreRules{}
+	if filepath.Clean(root) != "." {
+		// The project directory's ignore file is read before the root's, as
+		// the walk doesn't reach it.
+		if content, err := afero.ReadFile(f.Fs, IgnoreFileName); err == nil {
+			ignored.AddFile(".", content)
+		}
+	}
+	ignored.Add(root, f.Exclude...)
+	included.Add(root, f.Include...)
+
 	return func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if info.IsDir() {
+			return f.enterDirectory(path, root, ignored)
+		}
+
+		if f.skipFile(path, ignored, included) {
 			return nil
 		}
 
@@ -79,53 +123,117 @@ func (f *Filesystem) featuresAndStoriesWalkFunc(spec *Specification, warnings *e
 			if err := f.addFeatureFile(spec, path); err != nil {
 				*warnings = warnings.Append(err)
 			}
+		case FileExtActor:
+			if err := f.addActorFile(spec, path); err != nil {
+				*warnings = warnings.Append(err)
+			}
 		}
 
 		return nil
 	}
 }
 
+// enterDirectory skips ignored directories, and picks up the ignore file of
+// those that aren't.
+func (f *Filesystem) enterDirectory(path, root string, ignored *IgnoreRules) error {
+	if path != root && ignored.Ignored(path, true) {
+		return filepath.SkipDir
+	}
+
+	content, err := afero.ReadFile(f.Fs, filepath.Join(path, IgnoreFileName))
+	if err == nil {
+		ignored.AddFile(path, content)
+	}
+
+	return nil
+}
+
+func (f *Filesystem) skipFile(path string, ignored, included *IgnoreRules) bool {
+	if ignored.Ignored(path, false) {
+		return true
+	}
+	return len(f.Include) > 0 && !included.Matched(path)
+}
+
 // addFeatureFile tries to parse a file in a given afero.Fs and adds it to the
 // Filesystem state.
 func (f *Filesystem) addFeatureFile(spec *Specification, path string) error {
 
-	story, scenarios, err := f.parseFeatureFile(f.Fs, path)
+	story, rules, scenarios, err := f.parseFeatureFile(f.Fs, path)
 
 	if err != nil {
 		return err
 	}
 
 	spec.StorySources[path] = story
+	spec.RuleSources[story] = rules
 	spec.ScenarioSources[story] = scenarios
 
 	return nil
 }
 
-func (f *Filesystem) parseFeatureFile(fs afero.Fs, path string) (*Story, []*Scenario, error) {
+// addActorFile tries to parse an actor file in a given afero.Fs and adds it
+// to the Filesystem state.
+func (f *Filesystem) addActorFile(spec *Specification, path string) error {
+	content, err := afero.ReadFile(f.Fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", path, err)
+	}
+
+	actor, err := parseActor(content, path)
+	if err != nil {
+		return err
+	}
+
+	spec.ActorSources[path] = actor
+	return nil
+}
+
+func (f *Filesystem) dialect() string {
+	if f.Dialect == "" {
+		return DefaultDialect
+	}
+	return f.Dialect
+}
+
+func (f *Filesystem) parseFeatureFile(fs afero.Fs, path string) (*Story, []*Rule, []*Scenario, error) {
 	content, err := afero.ReadFile(fs, path)
 
 	if err != nil {
-		return &Story{}, nil, fmt.Errorf("failed to read %s: %s", path, err)
+		return &Story{}, nil, nil, fmt.Errorf("failed to read %s: %s", path, err)
 	}
 
 	buf := bytes.NewBuffer(content)
-	feature, err := gherkin.ParseFeature(buf)
+	document, err := gherkin.ParseGherkinDocumentForLanguage(buf, f.dialect())
 
 	if err != nil {
-		return &Story{}, nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		return &Story{}, nil, nil, fmt.Errorf("failed to parse %s: %s", path, err)
 	}
 
-	story := newStoryFromGherkinFeature(feature, path)
+	if document.Feature == nil {
+		return &Story{}, nil, nil, fmt.Errorf("failed to parse %s: no feature found", path)
+	}
+
+	story := newStoryFromGherkinFeature(document.Feature, path)
+	rules := []*Rule{}
 	scenarios := []*Scenario{}
 
-	for _, s := range feature.ScenarioDefinitions {
-		switch scenario := s.(type) {
-		case *gherkin.Scenario:
-			scenarios = append(scenarios, newScenarioFromGherkinScenario(scenario, story))
-		default:
-			return nil, nil, fmt.Errorf("Unhandled type %s", reflect.TypeOf(s))
+	for _, child := range document.Feature.Children {
+		if scenario := child.GetScenario(); scenario != nil {
+			scenarios = append(scenarios, newScenarioFromGherkinScenario(scenario, story, nil))
+		}
+
+		if r := child.GetRule(); r != nil {
+			rule := newRuleFromGherkinRule(r, story)
+			rules = append(rules, rule)
+
+			for _, ruleChild := range r.Children {
+				if scenario := ruleChild.GetScenario(); scenario != nil {
+					scenarios = append(scenarios, newScenarioFromGherkinScenario(scenario, story, rule))
+				}
+			}
 		}
 	}
 
-	return story, scenarios, nil
+	return story, rules, scenarios, nil
 }
@@ -0,0 +1,129 @@
+package specification
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/endiangroup/specstack/errors"
+	"github.com/spf13/afero"
+)
+
+// A RevisionSourcer lists and reads files as they were at a revision of a
+// version controlled project, such as a git commit, tag or branch.
+type RevisionSourcer interface {
+	RevisionFiles(revision, path string) ([]string, error)
+	RevisionFile(revision, path string) ([]byte, error)
+}
+
+// A Revision represents a specification as it was at a revision. The files
+// under Path, or each of Roots if set, are copied into memory the first time
+// they are needed and are then read like any other Filesystem.
+type Revision struct {
+	Sourcer  RevisionSourcer
+	Revision string
+	Path     string
+	Roots    []Root
+	Dialect  string
+	Include  []string
+	Exclude  []string
+
+	filesystem *Filesystem
+}
+
+// NewRevisionReader creates a Reader for the specification under path at the
+// given revision.
+func NewRevisionReader(sourcer RevisionSourcer, revision, path string) Reader {
+	return &Revision{
+		Sourcer:  sourcer,
+		Revision: revision,
+		Path:     path,
+		Dialect:  DefaultDialect,
+	}
+}
+
+// Read reads the specification as it was at the revision, returning the
+// spec, any warnings, and possibly a fatal error.
+func (r *Revision) Read() (*Specification, errors.Warnings, error) {
+	filesystem, err := r.loadFilesystem()
+	if err != nil {
+		return nil, errors.Warnings{}, err
+	}
+
+	return filesystem.Read()
+}
+
+func (r *Revision) ReadSource(s Sourcer) (io.Reader, error) {
+	filesystem, err := r.loadFilesystem()
+	if err != nil {
+		return nil, err
+	}
+
+	return filesystem.ReadSource(s)
+}
+
+func (r *Revision) loadFilesystem() (*Filesystem, error) {
+	if r.filesystem != nil {
+		return r.filesystem, nil
+	}
+
+	filesystem := &Filesystem{
+		Fs:      afero.NewMemMapFs(),
+		Path:    r.Path,
+		Roots:   r.Roots,
+		Dialect: r.Dialect,
+		Include: r.Include,
+		Exclude: r.Exclude,
+	}
+
+	for _, root := range filesystem.roots() {
+		if err := r.copyFiles(filesystem.Fs, root.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	// The project directory's ignore file applies to every root, so it's
+	// copied even when none of the roots hold it.
+	ignoreFiles, err := r.Sourcer.RevisionFiles(r.Revision, IgnoreFileName)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range ignoreFiles {
+		if err := r.copyFile(filesystem.Fs, file); err != nil {
+			return nil, err
+		}
+	}
+
+	r.filesystem = filesystem
+	return r.filesystem, nil
+}
+
+func (r *Revision) copyFiles(fs afero.Fs, path string) error {
+	files, err := r.Sourcer.RevisionFiles(r.Revision, path)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := r.copyFile(fs, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Revision) copyFile(fs afero.Fs, file string) error {
+	content, err := r.Sourcer.RevisionFile(r.Revision, file)
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, file, content, os.ModePerm)
+}
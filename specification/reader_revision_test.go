@@ -0,0 +1,90 @@
+package specification
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapRevisionSourcer map[string]map[string]string
+
+func (m mapRevisionSourcer) RevisionFiles(revision, path string) ([]string, error) {
+	files, ok := m[revision]
+	if !ok {
+		return nil, fmt.Errorf("unknown revision %s", revision)
+	}
+
+	output := []string{}
+	for file := range files {
+		if file == path || strings.HasPrefix(file, path+"/") {
+			output = append(output, file)
+		}
+	}
+	return output, nil
+}
+
+func (m mapRevisionSourcer) RevisionFile(revision, path string) ([]byte, error) {
+	content, ok := m[revision][path]
+	if !ok {
+		return nil, fmt.Errorf("no file %s at %s", path, revision)
+	}
+	return []byte(content), nil
+}
+
+func Test_ARevisionReaderCanReadASpecificationAtARevision(t *testing.T) {
+	sourcer := mapRevisionSourcer{
+		"v1": {
+			"features/a.feature":     mockFeatureA,
+			"features/sub/b.feature": mockFeatureB,
+			"README.md":              "not a feature",
+		},
+		"v2": {
+			"features/a.feature": mockFeatureA,
+		},
+	}
+
+	reader := NewRevisionReader(sourcer, "v1", "features")
+	spec, warnings, err := reader.Read()
+	require.Nil(t, err)
+	require.Len(t, warnings, 0)
+	require.Len(t, spec.Stories(), 2)
+
+	var story *Story
+	for _, s := range spec.Stories() {
+		if s.SourceIdentifier == "features/sub/b.feature" {
+			story = s
+		}
+	}
+	require.NotNil(t, story)
+
+	source, err := reader.ReadSource(story.FileSource())
+	require.Nil(t, err)
+	content, err := ioutil.ReadAll(source)
+	require.Nil(t, err)
+	require.Equal(t, mockFeatureB, string(content))
+
+	spec, _, err = NewRevisionReader(sourcer, "v2", "features").Read()
+	require.Nil(t, err)
+	require.Len(t, spec.Stories(), 1)
+
+	_, _, err = NewRevisionReader(sourcer, "v3", "features").Read()
+	require.Equal(t, fmt.Errorf("unknown revision v3"), err)
+}
+
+func Test_ARevisionReaderReadsTheProjectIgnoreFileAtTheRevision(t *testing.T) {
+	sourcer := mapRevisionSourcer{
+		"v1": {
+			".specignore":            "sub/\n",
+			"features/a.feature":     mockFeatureA,
+			"features/sub/b.feature": mockFeatureB,
+		},
+	}
+
+	spec, _, err := NewRevisionReader(sourcer, "v1", "features").Read()
+	require.Nil(t, err)
+	require.Len(t, spec.Stories(), 1)
+	require.Equal(t, "features/a.feature", spec.Stories()[0].SourceIdentifier)
+}
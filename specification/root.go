@@ -0,0 +1,67 @@
+package specification
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RootLabelSeparator separates the label of a root from the name of a story
+// in it, e.g. "billing:checkout".
+const RootLabelSeparator = ":"
+
+// A Root is one of the directories a specification is read from. Labels tell
+// apart stories with the same name in different roots.
+type Root struct {
+	Label string
+	Path  string
+}
+
+func (r Root) contains(source string) bool {
+	root := path.Clean(filepath.ToSlash(r.Path))
+	return root == "." || strings.HasPrefix(path.Clean(filepath.ToSlash(source)), root+"/")
+}
+
+func (r Root) trim(source string) string {
+	root := path.Clean(filepath.ToSlash(r.Path))
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(source)), root+"/")
+}
+
+// StoryRoot returns the root the story was read from.
+func (s *Specification) StoryRoot(story *Story) (Root, bool) {
+	for _, root := range s.Roots {
+		if root.contains(story.SourceIdentifier) {
+			return root, true
+		}
+	}
+	return Root{}, false
+}
+
+// splitRootLabel splits a "label:name" query into the label of a known root
+// and the name. Queries without a known label are returned unchanged.
+func (s *Specification) splitRootLabel(query string) (string, string) {
+	parts := strings.SplitN(query, RootLabelSeparator, 2)
+	if len(parts) != 2 {
+		return "", query
+	}
+	for _, root := range s.Roots {
+		if root.Label != "" && root.Label == parts[0] {
+			return parts[0], parts[1]
+		}
+	}
+	return "", query
+}
+
+// MapStoryRoot narrows the stories to those read from the root with the
+// given label.
+func MapStoryRoot(label string) QueryMapFunc {
+	return func(q *Query) {
+		stories := []*Story{}
+		for _, story := range q.storyPool() {
+			if root, ok := q.specification.StoryRoot(story); ok && root.Label == label {
+				stories = append(stories, story)
+			}
+		}
+		q.stories = stories
+	}
+}
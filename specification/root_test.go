@@ -0,0 +1,50 @@
+package specification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AFilesystemReaderMergesSeveralRootsIntoOneSpecification(t *testing.T) {
+	fs := newSpecificationFs(t, map[string]string{
+		"services/billing/features/checkout.feature": mockFeatureA,
+		"services/auth/features/checkout.feature":    mockFeatureB,
+		"services/auth/features/login.feature":       mockFeatureA,
+	})
+	reader := &Filesystem{
+		Fs: fs,
+		Roots: []Root{
+			{Label: "billing", Path: "services/billing/features"},
+			{Label: "auth", Path: "./services/auth/features"},
+		},
+	}
+
+	spec, _, err := reader.Read()
+	require.Nil(t, err)
+	require.Len(t, spec.Stories(), 3)
+	require.Equal(t, "services/billing/features", spec.Source)
+
+	story, err := spec.FindStory("billing:checkout")
+	require.Nil(t, err)
+	require.Equal(t, "services/billing/features/checkout.feature", story.SourceIdentifier)
+
+	root, ok := spec.StoryRoot(story)
+	require.True(t, ok)
+	require.Equal(t, "billing", root.Label)
+
+	story, err = spec.FindStory("auth:checkout")
+	require.Nil(t, err)
+	require.Equal(t, "services/auth/features/checkout.feature", story.SourceIdentifier)
+
+	story, err = spec.FindStory("login")
+	require.Nil(t, err)
+	require.Equal(t, "services/auth/features/login.feature", story.SourceIdentifier)
+
+	scenario, err := spec.FindScenario("First", "auth:checkout")
+	require.Nil(t, err)
+	require.Equal(t, "services/auth/features/checkout.feature", scenario.Story.SourceIdentifier)
+
+	_, err = spec.FindStory("checkout")
+	require.NotNil(t, err)
+}
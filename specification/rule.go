@@ -0,0 +1,29 @@
+package specification
+
+import (
+	messages "github.com/cucumber/cucumber-messages-go/v2"
+)
+
+// A Rule groups scenarios within a Story that illustrate a single business
+// rule. Rules may have their own Background, which applies after the Story's.
+type Rule struct {
+	*messages.Rule
+	Story *Story
+}
+
+func newRuleFromGherkinRule(rule *messages.Rule, story *Story) *Rule {
+	return &Rule{
+		Rule:  rule,
+		Story: story,
+	}
+}
+
+// Background returns the rule's Background, if it has one.
+func (r *Rule) Background() *messages.Background {
+	for _, child := range r.Children {
+		if background := child.GetBackground(); background != nil {
+			return background
+		}
+	}
+	return nil
+}
@@ -1,36 +1,226 @@
 package specification
 
 import (
+	"fmt"
 	"strings"
 
-	gherkin "github.com/DATA-DOG/godog/gherkin"
+	messages "github.com/cucumber/cucumber-messages-go/v2"
 	"github.com/endiangroup/specstack/fuzzy"
 )
 
+// ExampleSeparator separates a scenario outline query from the (1-based)
+// index of one of its Examples rows, e.g. "My outline#2".
+const ExampleSeparator = "#"
+
+// A Scenario is either a plain scenario, a scenario outline, or a single row of
+// a scenario outline's Examples tables. Outlines keep their Examples tables;
+// rows have their placeholders substituted and point back to their Outline.
+// Scenarios declared inside a Rule keep a reference to it.
 type Scenario struct {
-	*gherkin.Scenario
-	Story *Story
+	*messages.Scenario
+	Story    *Story
+	Rule     *Rule
+	Outline  *Scenario
+	Example  *messages.TableRow
+	examples []*Scenario
 }
 
-func newScenarioFromGherkinScenario(scenario *gherkin.Scenario, story *Story) *Scenario {
-	return &Scenario{
+func newScenarioFromGherkinScenario(scenario *messages.Scenario, story *Story, rule *Rule) *Scenario {
+	s := &Scenario{
 		Scenario: scenario,
 		Story:    story,
+		Rule:     rule,
+	}
+
+	for _, examples := range scenario.Examples {
+		if examples.TableHeader == nil {
+			continue
+		}
+		for _, row := range examples.TableBody {
+			s.examples = append(s.examples, newScenarioFromExampleRow(s, examples, row))
+		}
+	}
+
+	return s
+}
+
+func newScenarioFromExampleRow(outline *Scenario, examples *messages.Examples, row *messages.TableRow) *Scenario {
+	header := examples.TableHeader
+	steps := make([]*messages.Step, len(outline.Steps))
+	for i, step := range outline.Steps {
+		steps[i] = newStepFromExampleRow(step, header, row)
+	}
+
+	tags := append([]*messages.Tag{}, outline.Tags...)
+
+	return &Scenario{
+		Scenario: &messages.Scenario{
+			Location:    row.Location,
+			Tags:        append(tags, examples.Tags...),
+			Keyword:     outline.Keyword,
+			Name:        substituteExampleValues(outline.Name, header, row),
+			Description: outline.Description,
+			Steps:       steps,
+		},
+		Story:   outline.Story,
+		Rule:    outline.Rule,
+		Outline: outline,
+		Example: row,
 	}
 }
 
+func substituteExampleValues(text string, header, row *messages.TableRow) string {
+	for i, cell := range header.Cells {
+		if i >= len(row.Cells) {
+			break
+		}
+		text = strings.Replace(text, "<"+cell.Value+">", row.Cells[i].Value, -1)
+	}
+	return text
+}
+
+func newStepFromExampleRow(step *messages.Step, header, row *messages.TableRow) *messages.Step {
+	output := &messages.Step{
+		Location: step.Location,
+		Keyword:  step.Keyword,
+		Text:     substituteExampleValues(step.Text, header, row),
+		Argument: step.Argument,
+	}
+
+	if docString := step.GetDocString(); docString != nil {
+		substituted := *docString
+		substituted.Content = substituteExampleValues(docString.Content, header, row)
+		output.Argument = &messages.Step_DocString{DocString: &substituted}
+	}
+
+	if dataTable := step.GetDataTable(); dataTable != nil {
+		table := &messages.DataTable{Location: dataTable.Location}
+		for _, r := range dataTable.Rows {
+			cells := make([]*messages.TableCell, len(r.Cells))
+			for i, c := range r.Cells {
+				cells[i] = &messages.TableCell{
+					Location: c.Location,
+					Value:    substituteExampleValues(c.Value, header, row),
+				}
+			}
+			table.Rows = append(table.Rows, &messages.TableRow{Location: r.Location, Cells: cells})
+		}
+		output.Argument = &messages.Step_DataTable{DataTable: table}
+	}
+
+	return output
+}
+
+// Source returns what the scenario's metadata is keyed by: its ID if it has
+// one, or else its content.
 func (s *Scenario) Source() Source {
+	if id := s.ID(); id != "" {
+		return id.Source()
+	}
 	return Source{SourceTypeText, s.String()}
 }
 
+// LineNumber returns the line the scenario starts on. For Examples rows this
+// is the line of the row itself.
+func (s *Scenario) LineNumber() int {
+	if s.Location == nil {
+		return 0
+	}
+	return int(s.Location.Line)
+}
+
+// TagNames returns the names of the scenario's own tags followed by those it
+// inherits from its story.
+func (s *Scenario) TagNames() []string {
+	names := tagNames(s.Tags)
+	if s.Story != nil {
+		names = append(names, s.Story.TagNames()...)
+	}
+	return names
+}
+
+// IsOutline reports whether the scenario is a scenario outline with at least
+// one Examples table.
+func (s *Scenario) IsOutline() bool {
+	return len(s.Examples) > 0
+}
+
+// IsExample reports whether the scenario is a single Examples row expanded
+// from a scenario outline.
+func (s *Scenario) IsExample() bool {
+	return s.Outline != nil
+}
+
+// ExampleScenarios returns one scenario per Examples row of a scenario
+// outline, in the order they appear in the file.
+func (s *Scenario) ExampleScenarios() []*Scenario {
+	return s.examples
+}
+
+// ExampleScenario returns the Examples row at the given 1-based index.
+func (s *Scenario) ExampleScenario(index int) (*Scenario, error) {
+	if !s.IsOutline() {
+		return nil, fmt.Errorf("scenario %s is not a scenario outline", s.Name)
+	}
+	if index < 1 || index > len(s.examples) {
+		return nil, fmt.Errorf("scenario outline %s has no example %d", s.Name, index)
+	}
+	return s.examples[index-1], nil
+}
+
+// BackgroundSteps returns the steps of the Background of the scenario's
+// story, followed by those of its rule's Background, if they have them.
+func (s *Scenario) BackgroundSteps() []*messages.Step {
+	steps := []*messages.Step{}
+	if s.Story != nil {
+		if background := s.Story.Background(); background != nil {
+			steps = append(steps, background.Steps...)
+		}
+	}
+	if s.Rule != nil {
+		if background := s.Rule.Background(); background != nil {
+			steps = append(steps, background.Steps...)
+		}
+	}
+	return steps
+}
+
+// NormalisedLines returns the canonical form of the scenario: its name, the
+// steps of any Background, its own steps and any Examples tables.
 func (s *Scenario) NormalisedLines() []string {
+	return s.normalisedLines(true)
+}
+
+func (s *Scenario) normalisedLines(background bool) []string {
 	output := []string{s.Name}
+	if background {
+		for _, step := range s.BackgroundSteps() {
+			output = append(output, step.Text)
+		}
+	}
 	for _, step := range s.Steps {
 		output = append(output, step.Text)
 	}
+	for _, examples := range s.Examples {
+		if examples.TableHeader == nil {
+			continue
+		}
+		output = append(output, normalisedTableRow(examples.TableHeader))
+		for _, row := range examples.TableBody {
+			output = append(output, normalisedTableRow(row))
+		}
+	}
 	return output
 }
 
+func normalisedTableRow(row *messages.TableRow) string {
+	values := make([]string, len(row.Cells))
+	for i, cell := range row.Cells {
+		values[i] = cell.Value
+	}
+	return "| " + strings.Join(values, " | ") + " |"
+}
+
 func (s *Scenario) String() string {
 	return strings.Join(s.NormalisedLines(), "\n")
 }
@@ -39,15 +229,38 @@ func (s *Scenario) NormalisedSteps() string {
 	return strings.Join(s.NormalisedLines()[1:], "\n")
 }
 
-func ScenarioDistance(a, b *Scenario) float64 {
+type scenarioDistanceOptions struct {
+	ignoreBackground bool
+}
+
+// A ScenarioDistanceOption alters how ScenarioDistance compares scenarios.
+type ScenarioDistanceOption func(*scenarioDistanceOptions)
+
+// IgnoreBackground leaves Background steps out of the comparison, so that
+// scenarios are matched on their own name and steps only.
+func IgnoreBackground() ScenarioDistanceOption {
+	return func(o *scenarioDistanceOptions) {
+		o.ignoreBackground = true
+	}
+}
+
+func ScenarioDistance(a, b *Scenario, opts ...ScenarioDistanceOption) float64 {
+	o := &scenarioDistanceOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	linesA := a.normalisedLines(!o.ignoreBackground)
+	linesB := b.normalisedLines(!o.ignoreBackground)
+
 	if a.Name == "" || b.Name == "" {
-		return fuzzy.Strcmp(a.NormalisedSteps(), b.NormalisedSteps())
+		return fuzzy.Strcmp(strings.Join(linesA[1:], "\n"), strings.Join(linesB[1:], "\n"))
 	} else if len(a.Steps) == 0 || len(b.Steps) == 0 {
 		return fuzzy.Strcmp(a.Name, b.Name)
 	}
-	return fuzzy.Strcmp(a.String(), b.String())
+	return fuzzy.Strcmp(strings.Join(linesA, "\n"), strings.Join(linesB, "\n"))
 }
 
-func ScenarioRelated(a, b *Scenario) bool {
-	return ScenarioDistance(a, b) >= fuzzy.DistanceThreshold
+func ScenarioRelated(a, b *Scenario, opts ...ScenarioDistanceOption) bool {
+	return ScenarioDistance(a, b, opts...) >= fuzzy.DistanceThreshold
 }
@@ -365,3 +365,43 @@ func Test_ScenarioRelated_SpecstackScenarios(t *testing.T) {
 		})
 	}
 }
+
+func Test_AScenarioIncludesItsBackgroundInItsNormalisedLines(t *testing.T) {
+	scenario := newMockScenario(
+		t,
+		`Background:
+    Given I have a project directory
+
+  Scenario: Git not initialised for manual pull
+    But I have not initialised git
+    When I run "pull"`,
+	)
+
+	require.Equal(t, []string{
+		"Git not initialised for manual pull",
+		"I have a project directory",
+		"I have not initialised git",
+		`I run "pull"`,
+	}, scenario.NormalisedLines())
+}
+
+func Test_ScenarioDistance_CanIgnoreBackground(t *testing.T) {
+	body := `Scenario: Git not initialised for manual pull
+    But I have not initialised git
+    When I run "pull"`
+	withBackground := newMockScenario(
+		t,
+		`Background:
+    Given I have a project directory
+    And I have a story called "story1"
+    And I have a story called "story2"
+    And I have a story called "story3"
+
+  `+body,
+	)
+	withoutBackground := newMockScenario(t, body)
+
+	require.False(t, ScenarioRelated(withBackground, withoutBackground))
+	require.True(t, ScenarioRelated(withBackground, withoutBackground, IgnoreBackground()))
+	require.Equal(t, 1.0, ScenarioDistance(withBackground, withoutBackground, IgnoreBackground()))
+}
@@ -3,9 +3,15 @@ package specification
 import "reflect"
 
 type Snapshot struct {
+	Stories   []StorySnapshot
 	Scenarios []ScenarioSnapshot
 }
 
+type StorySnapshot struct {
+	StorySource Source
+	StoryID     string
+}
+
 type ScenarioSnapshot struct {
 	StorySource Source
 	StoryID     string
@@ -24,11 +30,29 @@ but not in B, and another that includes all elements that are in B
 but not in A.
 */
 func (a Snapshot) Diff(b Snapshot) (removed, added Snapshot) {
+	removed.Stories = a.diffStories(a.Stories, b.Stories)
+	added.Stories = a.diffStories(b.Stories, a.Stories)
 	removed.Scenarios = a.diffScenarios(a.Scenarios, b.Scenarios)
 	added.Scenarios = a.diffScenarios(b.Scenarios, a.Scenarios)
 	return
 }
 
+func (s Snapshot) diffStories(a, b []StorySnapshot) (removed []StorySnapshot) {
+	for _, sa := range a {
+		found := false
+		for _, sb := range b {
+			if sa == sb {
+				found = true
+				break
+			}
+		}
+		if !found {
+			removed = append(removed, sa)
+		}
+	}
+	return
+}
+
 func (s Snapshot) diffScenarios(a, b []ScenarioSnapshot) (removed []ScenarioSnapshot) {
 	for _, sa := range a {
 		found := false
@@ -1,6 +1,7 @@
 package specification
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/endiangroup/snaptest"
@@ -38,3 +39,31 @@ func Test_ASnapshotCanDiff(t *testing.T) {
 		snaptest.Snapshot(t, a)
 	})
 }
+
+func Test_ASnapshotDiffReportsBackgroundChangesOnEveryScenario(t *testing.T) {
+	feature := `Feature: Background changes
+
+  Background:
+	Given %s
+
+  Scenario: Alpha
+	Then this will work
+
+  Scenario: Omega
+	Then this will also work
+`
+	s0 := newSnapshotOfMockSpec(t,
+		map[string]string{
+			"features/a.feature": fmt.Sprintf(feature, "a project directory"),
+		},
+	)
+	s1 := newSnapshotOfMockSpec(t,
+		map[string]string{
+			"features/a.feature": fmt.Sprintf(feature, "a configured project directory"),
+		},
+	)
+
+	r, a := s0.Diff(s1)
+	require.Len(t, r.Scenarios, 2)
+	require.Len(t, a.Scenarios, 2)
+}
@@ -22,6 +22,7 @@ func NewSnapshotter(
 func (s *Snapshotter) Snapshot(spec *Specification) (Snapshot, error) {
 	q := NewQuery(spec).MapReduce(
 		MapScenarios(),
+		MapScenarioExamples(),
 		MapScenarioFileOrder(),
 	)
 	for _, fn := range s.MatchFuncs {
@@ -30,6 +31,12 @@ func (s *Snapshotter) Snapshot(spec *Specification) (Snapshot, error) {
 
 	snapshot := Snapshot{}
 
+	stories, err := s.snapshotStories(spec.Stories())
+	if err != nil {
+		return snapshot, err
+	}
+	snapshot.Stories = stories
+
 	scenarios, err := s.snapshotScenarios(q.Scenarios())
 	if err != nil {
 		return snapshot, err
@@ -39,10 +46,25 @@ func (s *Snapshotter) Snapshot(spec *Specification) (Snapshot, error) {
 	return snapshot, nil
 }
 
+func (s *Snapshotter) snapshotStories(stories []*Story) ([]StorySnapshot, error) {
+	ss := make([]StorySnapshot, len(stories))
+	for i, story := range stories {
+		storyID, err := s.DeterministicID(story.FileSource())
+		if err != nil {
+			return nil, err
+		}
+		ss[i] = StorySnapshot{
+			StorySource: story.FileSource(),
+			StoryID:     storyID,
+		}
+	}
+	return ss, nil
+}
+
 func (s *Snapshotter) snapshotScenarios(scenarios []*Scenario) ([]ScenarioSnapshot, error) {
 	ss := make([]ScenarioSnapshot, len(scenarios))
 	for i, scenario := range scenarios {
-		storyID, err := s.DeterministicID(scenario.Story)
+		storyID, err := s.DeterministicID(scenario.Story.FileSource())
 		if err != nil {
 			return nil, err
 		}
@@ -51,10 +73,10 @@ func (s *Snapshotter) snapshotScenarios(scenarios []*Scenario) ([]ScenarioSnapsh
 			return nil, err
 		}
 		ss[i] = ScenarioSnapshot{
-			StorySource: scenario.Story.Source(),
+			StorySource: scenario.Story.FileSource(),
 			StoryID:     storyID,
 			ScenarioID:  scenarioID,
-			LineNumber:  scenario.Location.Line,
+			LineNumber:  scenario.LineNumber(),
 		}
 	}
 	return ss, nil
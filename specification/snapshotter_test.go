@@ -23,3 +23,21 @@ func Test_ASnapshotterCanSnapshot(t *testing.T) {
 	require.Nil(t, err)
 	snaptest.Snapshot(t, ss)
 }
+
+func Test_ASnapshotterIncludesScenarioOutlineExamples(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/j.feature": mockFeatureJ,
+		},
+	)
+	snapshotter := NewSnapshotter(&MockReadSourcer{}, &MockObjectHasher{})
+
+	ss, err := snapshotter.Snapshot(spec)
+	require.Nil(t, err)
+
+	lines := []int{}
+	for _, s := range ss.Scenarios {
+		lines = append(lines, s.LineNumber)
+	}
+	require.ElementsMatch(t, []int{3, 6, 13, 14, 19}, lines)
+}
@@ -17,3 +17,8 @@ type Source struct {
 type Sourcer interface {
 	Source() Source
 }
+
+// Source returns itself, so that a Source can be read like any Sourcer.
+func (s Source) Source() Source {
+	return s
+}
@@ -4,39 +4,111 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
-	gherkin "github.com/DATA-DOG/godog/gherkin"
+	messages "github.com/cucumber/cucumber-messages-go/v2"
+	"github.com/endiangroup/specstack/fuzzy"
 )
 
 type Specification struct {
 	Source          string
+	Roots           []Root
 	StorySources    map[string]*Story
+	RuleSources     map[*Story][]*Rule
 	ScenarioSources map[*Story][]*Scenario
+	ActorSources    map[string]*Actor
 }
 
 func NewSpecification() *Specification {
 	return &Specification{
 		StorySources:    make(map[string]*Story),
+		RuleSources:     make(map[*Story][]*Rule),
 		ScenarioSources: make(map[*Story][]*Scenario),
+		ActorSources:    make(map[string]*Actor),
 	}
 }
 
 type Story struct {
-	*gherkin.Feature
+	*messages.Feature
 	SourceIdentifier string
 }
 
-func newStoryFromGherkinFeature(feature *gherkin.Feature, source string) *Story {
+func newStoryFromGherkinFeature(feature *messages.Feature, source string) *Story {
 	return &Story{
 		Feature:          feature,
 		SourceIdentifier: source,
 	}
 }
 
+// Source returns what the story's metadata is keyed by: its ID if it has
+// one, or else its normalised narrative, so that it survives edits to the
+// rest of the file.
 func (s *Story) Source() Source {
+	if id := s.ID(); id != "" {
+		return id.Source()
+	}
+	return Source{SourceTypeText, s.String()}
+}
+
+// FileSource returns the source of the file the story was read from.
+func (s *Story) FileSource() Source {
 	return Source{SourceTypeFile, s.SourceIdentifier}
 }
 
+// NormalisedLines returns the canonical form of the story: its name followed
+// by the non-blank lines of its description, trimmed of whitespace.
+func (s *Story) NormalisedLines() []string {
+	if s.Feature == nil {
+		return []string{}
+	}
+
+	output := []string{s.Name}
+	for _, line := range strings.Split(s.Description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			output = append(output, line)
+		}
+	}
+	return output
+}
+
+func (s *Story) String() string {
+	return strings.Join(s.NormalisedLines(), "\n")
+}
+
+// StoryDistance returns how similar the narratives of two stories are.
+func StoryDistance(a, b *Story) float64 {
+	return fuzzy.Strcmp(a.String(), b.String())
+}
+
+// TagNames returns the names of the tags on the story, including the "@".
+func (s *Story) TagNames() []string {
+	if s.Feature == nil {
+		return nil
+	}
+	return tagNames(s.Tags)
+}
+
+func tagNames(tags []*messages.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// Background returns the story's Background, if it has one.
+func (s *Story) Background() *messages.Background {
+	if s.Feature == nil {
+		return nil
+	}
+	for _, child := range s.Children {
+		if background := child.GetBackground(); background != nil {
+			return background
+		}
+	}
+	return nil
+}
+
 // Stories fetches a list of features derived from loaded feature files.
 // Features are returned in alphabetical order of the file name that contains
 // them.
@@ -73,15 +145,38 @@ func (s *Specification) Scenarios(stories ...*Story) []*Scenario {
 	return scenarios
 }
 
+// Rules fetches a list of rules from all loaded feature files, in the order
+// they appear in their feature file, grouped by file name in alphabetical
+// order.
+func (s *Specification) Rules(stories ...*Story) []*Rule {
+	rules := []*Rule{}
+
+	if len(stories) == 0 {
+		stories = s.Stories()
+	}
+
+	for _, story := range stories {
+		rules = append(rules, s.RuleSources[story]...)
+	}
+	return rules
+}
+
 // FindStory performs a fuzzy match on the source (usually file name) and
 // name of all known stories, then returns the closest match, if any. The base
 // source (usually directory path) and any file extensions are omitted from the
 // match. In the event of a tie (that is, two roughly equal matches) then an
-// error is returned.
-func (f *Specification) FindStory(input string) (*Story, error) {
-	matches := NewQuery(f).MapReduce(
+// error is returned. Any filters are applied before matching, narrowing the
+// stories that are considered. Stories in a labelled root can be addressed
+// as "label:story".
+func (f *Specification) FindStory(input string, filters ...QueryMapFunc) (*Story, error) {
+	label, name := f.splitRootLabel(input)
+	if label != "" {
+		filters = append([]QueryMapFunc{MapStoryRoot(label)}, filters...)
+	}
+
+	matches := NewQuery(f).MapReduce(filters...).MapReduce(
 		MapStories(
-			ReduceClosestMatch(input),
+			ReduceClosestMatch(name),
 			ReduceMax(2),
 		),
 		MapUniqueStories(),
@@ -105,9 +200,13 @@ func (f *Specification) FindStory(input string) (*Story, error) {
 // FindScenario performs a fuzzy match on the name of all scenarios
 // in scope. The scope is either all scenarios, or only scenarios in
 // the provided story name. In the event of a tie (that is, two roughly
-// equal matches) an error is returned
-func (s *Specification) FindScenario(query, storyName string) (*Scenario, error) {
-	q := s.findScenarioQuery(query, storyName)
+// equal matches) an error is returned. A single Examples row of a
+// scenario outline can be addressed by suffixing the query with
+// ExampleSeparator and the row's 1-based index, e.g. "outline#2". Any
+// filters narrow the scenarios that are considered.
+func (s *Specification) FindScenario(query, storyName string, filters ...QueryMapFunc) (*Scenario, error) {
+	term, example := splitExampleIndex(query)
+	q := s.findScenarioQuery(term, storyName, filters)
 	matches := q.Scenarios()
 
 	switch {
@@ -130,27 +229,53 @@ func (s *Specification) FindScenario(query, storyName string) (*Scenario, error)
 		)
 	}
 
+	if example > 0 {
+		return matches[0].ExampleScenario(example)
+	}
+
 	return matches[0], nil
 }
 
-func (s *Specification) findScenarioQuery(term, storyName string) *Query {
+func splitExampleIndex(query string) (string, int) {
+	i := strings.LastIndex(query, ExampleSeparator)
+	if i < 0 {
+		return query, 0
+	}
+
+	index, err := strconv.Atoi(query[i+len(ExampleSeparator):])
+	if err != nil || index < 1 {
+		return query, 0
+	}
+
+	return query[:i], index
+}
+
+func (s *Specification) findScenarioQuery(term, storyName string, filters []QueryMapFunc) *Query {
 	q := NewQuery(s)
 
 	if storyName != "" {
+		label, name := s.splitRootLabel(storyName)
+		if label != "" {
+			q.MapReduce(MapStoryRoot(label))
+		}
 		q.MapReduce(
 			MapStories(
-				ReduceClosestMatch(storyName),
+				ReduceClosestMatch(name),
 				ReduceMax(1),
 			),
 		)
 	}
 
-	if val, err := strconv.Atoi(term); err == nil {
+	if strings.HasPrefix(term, IDTagPrefix) {
+		q.MapReduce(
+			MapScenarioID(ScenarioID(strings.TrimPrefix(term, IDTagPrefix))),
+		).MapReduce(filters...)
+	} else if val, err := strconv.Atoi(term); err == nil {
 		q.MapReduce(
 			MapScenarioIndex(val),
-		)
+		).MapReduce(filters...)
 	} else {
-		q.MapReduce(
+		q.MapReduce(filters...).MapReduce(
 			MapScenarios(
 				ReduceClosestMatch(term),
 				ReduceMax(2),
@@ -94,6 +94,26 @@ const (
 
   Scenario: Scenario E
 	Then this is step E
+`
+	mockFeatureJ = `Feature: Outlines
+
+  Scenario: Plain scenario
+	Then this will work
+
+  Scenario Outline: Eating <start> cucumbers
+	Given there are <start> cucumbers
+	When I eat <eat> cucumbers
+	Then I should have <left> cucumbers
+
+	Examples:
+	  | start | eat | left |
+	  | 12    | 5   | 7    |
+	  | 20    | 5   | 15   |
+
+	@more
+	Examples: More
+	  | start | eat | left |
+	  | 3     | 3   | 0    |
 `
 )
 
@@ -232,3 +252,149 @@ func Test_ASpecificationCanIndexScenarios(t *testing.T) {
 		})
 	}
 }
+
+func Test_ASpecificationCanReadScenarioOutlines(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/j.feature": mockFeatureJ,
+		},
+	)
+
+	scenarios := spec.Scenarios()
+	require.Len(t, scenarios, 2)
+	require.False(t, scenarios[0].IsOutline())
+	require.True(t, scenarios[1].IsOutline())
+
+	examples := scenarios[1].ExampleScenarios()
+	require.Len(t, examples, 3)
+	for _, example := range examples {
+		require.True(t, example.IsExample())
+		require.Equal(t, scenarios[1], example.Outline)
+	}
+
+	require.Equal(t, "Eating 20 cucumbers", examples[1].Name)
+	require.Equal(t, 14, examples[1].LineNumber())
+	require.Equal(t, []string{
+		"Eating 20 cucumbers",
+		"there are 20 cucumbers",
+		"I eat 5 cucumbers",
+		"I should have 15 cucumbers",
+	}, examples[1].NormalisedLines())
+	require.Len(t, examples[2].Tags, 1)
+	require.Equal(t, "@more", examples[2].Tags[0].Name)
+	require.Contains(t, scenarios[1].String(), "| 3 | 3 | 0 |")
+}
+
+func Test_ASpecificationCanAddressScenarioOutlineExamples(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/j.feature": mockFeatureJ,
+		},
+	)
+
+	for _, test := range []struct {
+		term string
+		name string
+		line int
+		err  error
+	}{
+		{term: "Eating cucumbers", name: "Eating <start> cucumbers", line: 6},
+		{term: "Eating cucumbers#1", name: "Eating 12 cucumbers", line: 13},
+		{term: "Eating cucumbers#3", name: "Eating 3 cucumbers", line: 19},
+		{term: "2#2", name: "Eating 20 cucumbers", line: 14},
+		{term: "Eating cucumbers#4", err: fmt.Errorf("scenario outline Eating <start> cucumbers has no example 4")},
+		{term: "Plain scenario#1", err: fmt.Errorf("scenario Plain scenario is not a scenario outline")},
+	} {
+		t.Run(test.term, func(t *testing.T) {
+			scenario, err := spec.FindScenario(test.term, "Outlines")
+
+			if test.err == nil {
+				require.Nil(t, err)
+				require.Equal(t, test.name, scenario.Name)
+				require.Equal(t, test.line, scenario.LineNumber())
+			} else {
+				require.Equal(t, test.err, err)
+				require.Nil(t, scenario)
+			}
+		})
+	}
+}
+
+func Test_ASpecificationCanReadRules(t *testing.T) {
+	spec := generateAndReadSpec(t,
+		map[string]string{
+			"features/k.feature": `Feature: Rules
+
+  Background:
+    Given a configured project
+
+  Example: Outside a rule
+    Then this will work
+
+  Rule: Stories must exist
+
+    Background:
+      Given a story
+
+    Example: Inside a rule
+      Then this will also work
+`,
+		},
+	)
+
+	story := spec.Stories()[0]
+	rules := spec.Rules()
+	require.Len(t, rules, 1)
+	require.Equal(t, "Stories must exist", rules[0].Name)
+	require.Equal(t, story, rules[0].Story)
+
+	scenarios := spec.Scenarios()
+	require.Len(t, scenarios, 2)
+	require.Nil(t, scenarios[0].Rule)
+	require.Equal(t, rules[0], scenarios[1].Rule)
+	require.Equal(t, []string{
+		"Inside a rule",
+		"a configured project",
+		"a story",
+		"this will also work",
+	}, scenarios[1].NormalisedLines())
+}
+
+func Test_ASpecificationCanBeReadInAnotherDialect(t *testing.T) {
+	fs := newSpecificationFs(t, map[string]string{
+		"features/l.feature": `Fonctionnalité: Dialectes
+
+  Scénario: Premier
+    Alors ça marche
+`,
+	})
+	reader := &Filesystem{Fs: fs, Path: "features", Dialect: "fr"}
+
+	spec, warnings, err := reader.Read()
+	require.Nil(t, err)
+	require.Len(t, warnings, 0)
+	require.Equal(t, "Premier", spec.Scenarios()[0].Name)
+
+	reader.Dialect = "xx"
+	_, _, err = reader.Read()
+	require.Equal(t, fmt.Errorf("unknown gherkin dialect xx"), err)
+}
+
+func Test_AStoryIsKeyedByItsNarrativeOrID(t *testing.T) {
+	source := func(content string) Source {
+		spec := generateAndReadSpec(t, map[string]string{"features/a.feature": content})
+		return spec.Stories()[0].Source()
+	}
+
+	original := source("Feature: Checkout\n  As a customer\n  I want to pay\n\n  Scenario: Card\n")
+	require.Equal(t, Source{SourceTypeText, "Checkout\nAs a customer\nI want to pay"}, original)
+
+	require.Equal(t, original, source("Feature: Checkout\n    As a customer\n\n    I want to pay\n\n"+
+		"  Scenario: Card\n  Scenario: Cash\n"))
+	require.NotEqual(t, original, source("Feature: Paying\n  As a customer\n  I want to pay\n"))
+
+	require.Equal(t,
+		Source{SourceTypeText, "story @id:checkout"},
+		source("@id:checkout\nFeature: Paying\n  As a customer\n"),
+	)
+}
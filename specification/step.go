@@ -0,0 +1,82 @@
+package specification
+
+import (
+	"fmt"
+	"strconv"
+
+	messages "github.com/cucumber/cucumber-messages-go/v2"
+	"github.com/endiangroup/specstack/fuzzy"
+)
+
+// A Step is one of a scenario's own steps, along with its 1-based position in
+// the scenario.
+type Step struct {
+	*messages.Step
+	Scenario *Scenario
+	Index    int
+}
+
+// Source returns what the step's metadata is keyed by: the key of its
+// scenario and its text.
+func (s *Step) Source() Source {
+	return StepSource(s.Scenario, s.Text)
+}
+
+// StepSource returns the key of metadata of a step with the given text in the
+// scenario keyed by scenario.
+func StepSource(scenario Sourcer, text string) Source {
+	return Source{SourceTypeText, scenario.Source().Body + "\nstep: " + text}
+}
+
+// ScenarioSteps returns the scenario's own steps, leaving out those of any
+// Background.
+func (s *Scenario) ScenarioSteps() []*Step {
+	steps := make([]*Step, len(s.Steps))
+	for i, step := range s.Steps {
+		steps[i] = &Step{step, s, i + 1}
+	}
+	return steps
+}
+
+// FindStep returns the step at a 1-based index, or else the step whose text
+// most closely matches the query. In the event of a tie an error is returned.
+func (s *Scenario) FindStep(query string) (*Step, error) {
+	steps := s.ScenarioSteps()
+
+	if index, err := strconv.Atoi(query); err == nil {
+		if index < 1 || index > len(steps) {
+			return nil, fmt.Errorf("scenario %s has no step %d", s.Name, index)
+		}
+		return steps[index-1], nil
+	}
+
+	texts := []string{}
+	for _, step := range steps {
+		texts = append(texts, step.Text)
+	}
+	matches := ReduceClosestMatch(query)(texts)
+
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("no step matching %s", query)
+
+	case len(matches) > 1 && matches[0] != matches[1]:
+		return nil, fmt.Errorf(
+			"step query is ambiguous. The most similar steps are '%s' and '%s'",
+			matches[0],
+			matches[1],
+		)
+	}
+
+	for _, step := range steps {
+		if step.Text == matches[0] {
+			return step, nil
+		}
+	}
+	return nil, fmt.Errorf("no step matching %s", query)
+}
+
+// StepDistance returns how similar the text of two steps is.
+func StepDistance(a, b *Step) float64 {
+	return fuzzy.Strcmp(a.Text, b.Text)
+}
@@ -0,0 +1,61 @@
+package specification
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mockFeatureSteps = `Feature: Checkout
+
+  Background:
+    Given I am signed in
+
+  Scenario: Pay by card
+    Given I have a card
+    When I pay for my order
+    Then the payments sandbox is charged
+`
+
+func Test_AScenarioCanAddressItsSteps(t *testing.T) {
+	spec := generateAndReadSpec(t, map[string]string{"features/a.feature": mockFeatureSteps})
+	scenario, err := spec.FindScenario("Pay by card", "")
+	require.Nil(t, err)
+
+	for _, test := range []struct {
+		query string
+		index int
+		err   error
+	}{
+		{query: "1", index: 1},
+		{query: "3", index: 3},
+		{query: "the payments sandbox is charged", index: 3},
+		{query: "I pay for my orders", index: 2},
+		{query: "0", err: fmt.Errorf("scenario Pay by card has no step 0")},
+		{query: "4", err: fmt.Errorf("scenario Pay by card has no step 4")},
+		{query: "zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", err: fmt.Errorf("no step matching zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz")},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			step, err := scenario.FindStep(test.query)
+			if test.err != nil {
+				require.Equal(t, test.err, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, test.index, step.Index)
+			require.Equal(t, scenario, step.Scenario)
+		})
+	}
+}
+
+func Test_AStepIsKeyedByItsScenarioAndText(t *testing.T) {
+	spec := generateAndReadSpec(t, map[string]string{"features/a.feature": mockFeatureSteps})
+	scenario, err := spec.FindScenario("Pay by card", "")
+	require.Nil(t, err)
+
+	step, err := scenario.FindStep("1")
+	require.Nil(t, err)
+	require.Equal(t, Source{SourceTypeText, scenario.String() + "\nstep: I have a card"}, step.Source())
+	require.Equal(t, Source{SourceTypeText, "@id:pay\nstep: I have a card"}, StepSource(ScenarioID("pay"), step.Text))
+}
@@ -0,0 +1,190 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A TagExpression is a boolean expression over Gherkin tags, such as
+// "@wip and not (@slow or @manual)".
+type TagExpression interface {
+	Match(tags []string) bool
+}
+
+type tagLiteral string
+
+func (t tagLiteral) Match(tags []string) bool {
+	for _, tag := range tags {
+		if tag == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+type tagNot struct {
+	expression TagExpression
+}
+
+func (t tagNot) Match(tags []string) bool {
+	return !t.expression.Match(tags)
+}
+
+type tagAnd struct {
+	left, right TagExpression
+}
+
+func (t tagAnd) Match(tags []string) bool {
+	return t.left.Match(tags) && t.right.Match(tags)
+}
+
+type tagOr struct {
+	left, right TagExpression
+}
+
+func (t tagOr) Match(tags []string) bool {
+	return t.left.Match(tags) || t.right.Match(tags)
+}
+
+// ParseTagExpression parses a tag expression made of tags, "and", "or",
+// "not" and parentheses. "not" binds tightest, then "and", then "or".
+func ParseTagExpression(input string) (TagExpression, error) {
+	p := &tagParser{tokens: tokeniseTagExpression(input)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty tag expression")
+	}
+
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected '%s' in tag expression", token)
+	}
+
+	return expression, nil
+}
+
+func tokeniseTagExpression(input string) []string {
+	tokens := []string{}
+	current := strings.Builder{}
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+type tagParser struct {
+	tokens   []string
+	position int
+}
+
+func (p *tagParser) peek() (string, bool) {
+	if p.position >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.position], true
+}
+
+func (p *tagParser) next() (string, bool) {
+	token, ok := p.peek()
+	if ok {
+		p.position++
+	}
+	return token, ok
+}
+
+func (p *tagParser) parseOr() (TagExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if token, ok := p.peek(); !ok || token != "or" {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = tagOr{left, right}
+	}
+}
+
+func (p *tagParser) parseAnd() (TagExpression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if token, ok := p.peek(); !ok || token != "and" {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = tagAnd{left, right}
+	}
+}
+
+func (p *tagParser) parseNot() (TagExpression, error) {
+	if token, ok := p.peek(); ok && token == "not" {
+		p.next()
+		expression, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{expression}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *tagParser) parsePrimary() (TagExpression, error) {
+	token, ok := p.next()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unexpected end of tag expression")
+
+	case token == "(":
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.next(); !ok || closing != ")" {
+			return nil, fmt.Errorf("missing ')' in tag expression")
+		}
+		return expression, nil
+
+	case strings.HasPrefix(token, "@") && len(token) > 1:
+		return tagLiteral(token), nil
+	}
+
+	return nil, fmt.Errorf("unexpected '%s' in tag expression", token)
+}
@@ -0,0 +1,51 @@
+package specification
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ATagExpressionCanMatchTags(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		tags       []string
+		match      bool
+	}{
+		{expression: "@wip", tags: []string{"@wip"}, match: true},
+		{expression: "@wip", tags: []string{"@slow"}, match: false},
+		{expression: "not @wip", tags: []string{"@slow"}, match: true},
+		{expression: "@wip and not @slow", tags: []string{"@wip"}, match: true},
+		{expression: "@wip and not @slow", tags: []string{"@wip", "@slow"}, match: false},
+		{expression: "@wip or @slow", tags: []string{"@slow"}, match: true},
+		{expression: "@a or @b and @c", tags: []string{"@a"}, match: true},
+		{expression: "(@a or @b) and @c", tags: []string{"@a"}, match: false},
+		{expression: "not (@a or @b)", tags: []string{}, match: true},
+	} {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := ParseTagExpression(test.expression)
+			require.Nil(t, err)
+			require.Equal(t, test.match, expression.Match(test.tags))
+		})
+	}
+}
+
+func Test_ATagExpressionReportsSyntaxErrors(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		err        error
+	}{
+		{expression: "", err: fmt.Errorf("empty tag expression")},
+		{expression: "wip", err: fmt.Errorf("unexpected 'wip' in tag expression")},
+		{expression: "@wip and", err: fmt.Errorf("unexpected end of tag expression")},
+		{expression: "(@wip", err: fmt.Errorf("missing ')' in tag expression")},
+		{expression: "@wip @slow", err: fmt.Errorf("unexpected '@slow' in tag expression")},
+	} {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := ParseTagExpression(test.expression)
+			require.Equal(t, test.err, err)
+			require.Nil(t, expression)
+		})
+	}
+}